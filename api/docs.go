@@ -15,8 +15,9 @@ const docTemplate = `{
     "host": "{{.Host}}",
     "basePath": "{{.BasePath}}",
     "paths": {
-        "/live": {
+        "/.well-known/jwks.json": {
             "get": {
+                "description": "Publishes the public keys of asymmetric token signing keys, HMAC keys are never listed.",
                 "consumes": [
                     "application/json"
                 ],
@@ -24,16 +25,360 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "Health"
+                    "Auth"
                 ],
-                "summary": "Health check",
+                "summary": "Public signing keys",
                 "responses": {
                     "200": {
-                        "description": "ok",
+                        "description": "key set",
+                        "schema": {
+                            "$ref": "#/definitions/wb-test_pkg_utils_jwt.JWKSet"
+                        }
+                    }
+                }
+            }
+        },
+        "/api-keys": {
+            "get": {
+                "description": "Lists all API keys, revoked and expired ones included. Keys themselves are never returned, only their prefix.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "API keys"
+                ],
+                "summary": "List API keys",
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "API keys",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/wb-test_internal_models.APIKey"
+                            }
+                        }
+                    },
+                    "401": {
+                        "description": "missing, expired or invalid token",
+                        "schema": {
+                            "$ref": "#/definitions/wb-test_pkg_utils_http-utils.ErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "missing role or scope",
+                        "schema": {
+                            "$ref": "#/definitions/wb-test_pkg_utils_http-utils.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "internal server error",
+                        "schema": {
+                            "$ref": "#/definitions/wb-test_pkg_utils_http-utils.ErrorResponse"
+                        }
+                    }
+                }
+            },
+            "post": {
+                "description": "Creates an API key for a service caller. The key is only returned in this response, store it right away.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "API keys"
+                ],
+                "summary": "Create API key",
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "parameters": [
+                    {
+                        "description": "API key",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/wb-test_internal_models.CreateAPIKeyRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "created key",
+                        "schema": {
+                            "$ref": "#/definitions/wb-test_internal_models.CreatedAPIKey"
+                        }
+                    },
+                    "400": {
+                        "description": "invalid request body",
+                        "schema": {
+                            "$ref": "#/definitions/wb-test_pkg_utils_http-utils.ErrorResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "missing, expired or invalid token",
+                        "schema": {
+                            "$ref": "#/definitions/wb-test_pkg_utils_http-utils.ErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "missing role or scope",
+                        "schema": {
+                            "$ref": "#/definitions/wb-test_pkg_utils_http-utils.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "internal server error",
+                        "schema": {
+                            "$ref": "#/definitions/wb-test_pkg_utils_http-utils.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/api-keys/{id}": {
+            "delete": {
+                "description": "Revokes an API key, requests with it are refused from now on.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "API keys"
+                ],
+                "summary": "Revoke API key",
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "API key ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "revoked",
+                        "schema": {
+                            "$ref": "#/definitions/wb-test_pkg_utils_http-utils.Status"
+                        }
+                    },
+                    "400": {
+                        "description": "invalid id",
+                        "schema": {
+                            "$ref": "#/definitions/wb-test_pkg_utils_http-utils.ErrorResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "missing, expired or invalid token",
+                        "schema": {
+                            "$ref": "#/definitions/wb-test_pkg_utils_http-utils.ErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "missing role or scope",
+                        "schema": {
+                            "$ref": "#/definitions/wb-test_pkg_utils_http-utils.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "api key not found",
+                        "schema": {
+                            "$ref": "#/definitions/wb-test_pkg_utils_http-utils.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "internal server error",
+                        "schema": {
+                            "$ref": "#/definitions/wb-test_pkg_utils_http-utils.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/auth/login": {
+            "post": {
+                "description": "Exchanges a username and password for an access and refresh token. Repeated failures lock the user for a while.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Auth"
+                ],
+                "summary": "Log in",
+                "parameters": [
+                    {
+                        "description": "Credentials",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/wb-test_internal_models.LoginRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "token pair",
+                        "schema": {
+                            "$ref": "#/definitions/wb-test_internal_models.TokenPair"
+                        }
+                    },
+                    "400": {
+                        "description": "invalid request body",
+                        "schema": {
+                            "$ref": "#/definitions/wb-test_pkg_utils_http-utils.ErrorResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "invalid username or password",
+                        "schema": {
+                            "$ref": "#/definitions/wb-test_pkg_utils_http-utils.ErrorResponse"
+                        }
+                    },
+                    "423": {
+                        "description": "user locked",
+                        "schema": {
+                            "$ref": "#/definitions/wb-test_pkg_utils_http-utils.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "internal server error",
+                        "schema": {
+                            "$ref": "#/definitions/wb-test_pkg_utils_http-utils.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/auth/logout": {
+            "post": {
+                "description": "Revokes the access token of the request and, when given, every refresh token of the same login.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Auth"
+                ],
+                "summary": "Log out",
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "parameters": [
+                    {
+                        "description": "Refresh token",
+                        "name": "request",
+                        "in": "body",
+                        "required": false,
+                        "schema": {
+                            "$ref": "#/definitions/wb-test_internal_models.RefreshRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "logged out",
                         "schema": {
                             "$ref": "#/definitions/wb-test_pkg_utils_http-utils.Status"
                         }
                     },
+                    "400": {
+                        "description": "invalid request body",
+                        "schema": {
+                            "$ref": "#/definitions/wb-test_pkg_utils_http-utils.ErrorResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "missing, expired or invalid token",
+                        "schema": {
+                            "$ref": "#/definitions/wb-test_pkg_utils_http-utils.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "internal server error",
+                        "schema": {
+                            "$ref": "#/definitions/wb-test_pkg_utils_http-utils.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/auth/refresh": {
+            "post": {
+                "description": "Exchanges a refresh token for a new access and refresh token. Each refresh token works once, reusing one revokes all tokens rotated from the same login.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Auth"
+                ],
+                "summary": "Refresh tokens",
+                "parameters": [
+                    {
+                        "description": "Refresh token",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/wb-test_internal_models.RefreshRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "new token pair",
+                        "schema": {
+                            "$ref": "#/definitions/wb-test_internal_models.TokenPair"
+                        }
+                    },
+                    "400": {
+                        "description": "invalid request body",
+                        "schema": {
+                            "$ref": "#/definitions/wb-test_pkg_utils_http-utils.ErrorResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "invalid or reused refresh token",
+                        "schema": {
+                            "$ref": "#/definitions/wb-test_pkg_utils_http-utils.ErrorResponse"
+                        }
+                    },
+                    "423": {
+                        "description": "user locked",
+                        "schema": {
+                            "$ref": "#/definitions/wb-test_pkg_utils_http-utils.ErrorResponse"
+                        }
+                    },
                     "500": {
                         "description": "internal server error",
                         "schema": {
@@ -42,9 +387,1090 @@ const docTemplate = `{
                     }
                 }
             }
-        }
-    },
-    "definitions": {
+        },
+        "/customers/{customer_id}/orders": {
+            "get": {
+                "description": "Returns up to 100 most recent orders of the customer.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Orders"
+                ],
+                "summary": "Get orders of a customer",
+                "security": [
+                    {
+                        "BearerAuth": []
+                    },
+                    {
+                        "APIKeyAuth": []
+                    }
+                ],
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Customer ID",
+                        "name": "customer_id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "orders",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/wb-test_internal_models.Order"
+                            }
+                        }
+                    },
+                    "401": {
+                        "description": "missing, expired or invalid token",
+                        "schema": {
+                            "$ref": "#/definitions/wb-test_pkg_utils_http-utils.ErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "missing role or scope",
+                        "schema": {
+                            "$ref": "#/definitions/wb-test_pkg_utils_http-utils.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "internal server error",
+                        "schema": {
+                            "$ref": "#/definitions/wb-test_pkg_utils_http-utils.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/dead-letters": {
+            "get": {
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Dead letters"
+                ],
+                "summary": "List dead-lettered order messages",
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Max number of letters (default 50, max 1000)",
+                        "name": "limit",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "dead letters",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/wb-test_pkg_broker.DeadLetter"
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "invalid limit",
+                        "schema": {
+                            "$ref": "#/definitions/wb-test_pkg_utils_http-utils.ErrorResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "missing, expired or invalid token",
+                        "schema": {
+                            "$ref": "#/definitions/wb-test_pkg_utils_http-utils.ErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "missing role or scope",
+                        "schema": {
+                            "$ref": "#/definitions/wb-test_pkg_utils_http-utils.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "internal server error",
+                        "schema": {
+                            "$ref": "#/definitions/wb-test_pkg_utils_http-utils.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/dead-letters/{sequence}/replay": {
+            "post": {
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Dead letters"
+                ],
+                "summary": "Replay a dead-lettered order message to its original subject",
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Dead letter sequence",
+                        "name": "sequence",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "replayed",
+                        "schema": {
+                            "$ref": "#/definitions/wb-test_pkg_utils_http-utils.Status"
+                        }
+                    },
+                    "400": {
+                        "description": "invalid sequence",
+                        "schema": {
+                            "$ref": "#/definitions/wb-test_pkg_utils_http-utils.ErrorResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "missing, expired or invalid token",
+                        "schema": {
+                            "$ref": "#/definitions/wb-test_pkg_utils_http-utils.ErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "missing role or scope",
+                        "schema": {
+                            "$ref": "#/definitions/wb-test_pkg_utils_http-utils.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "dead letter not found",
+                        "schema": {
+                            "$ref": "#/definitions/wb-test_pkg_utils_http-utils.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "internal server error",
+                        "schema": {
+                            "$ref": "#/definitions/wb-test_pkg_utils_http-utils.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/live": {
+            "get": {
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Health"
+                ],
+                "summary": "Health check",
+                "responses": {
+                    "200": {
+                        "description": "ok",
+                        "schema": {
+                            "$ref": "#/definitions/wb-test_pkg_utils_http-utils.Status"
+                        }
+                    },
+                    "500": {
+                        "description": "internal server error",
+                        "schema": {
+                            "$ref": "#/definitions/wb-test_pkg_utils_http-utils.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/orders": {
+            "get": {
+                "description": "Lists orders newest first. Pass next_cursor from the previous page as cursor to continue.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Orders"
+                ],
+                "summary": "List orders",
+                "security": [
+                    {
+                        "BearerAuth": []
+                    },
+                    {
+                        "APIKeyAuth": []
+                    }
+                ],
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Page size (default 20, max 100)",
+                        "name": "limit",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Cursor from the previous page",
+                        "name": "cursor",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Customer ID",
+                        "name": "customer_id",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Track number",
+                        "name": "track_number",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Delivery service",
+                        "name": "delivery_service",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Payment provider",
+                        "name": "payment_provider",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Payment currency",
+                        "name": "payment_currency",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Item brand",
+                        "name": "brand",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Item nm_id",
+                        "name": "nm_id",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Created at or after (RFC 3339)",
+                        "name": "created_from",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Created before (RFC 3339)",
+                        "name": "created_to",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "orders",
+                        "schema": {
+                            "$ref": "#/definitions/wb-test_internal_models.OrderPage"
+                        }
+                    },
+                    "400": {
+                        "description": "invalid query",
+                        "schema": {
+                            "$ref": "#/definitions/wb-test_pkg_utils_http-utils.ErrorResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "missing, expired or invalid token",
+                        "schema": {
+                            "$ref": "#/definitions/wb-test_pkg_utils_http-utils.ErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "missing role or scope",
+                        "schema": {
+                            "$ref": "#/definitions/wb-test_pkg_utils_http-utils.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "internal server error",
+                        "schema": {
+                            "$ref": "#/definitions/wb-test_pkg_utils_http-utils.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/orders/by-track/{track_number}": {
+            "get": {
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Orders"
+                ],
+                "summary": "Get orders by track number",
+                "security": [
+                    {
+                        "BearerAuth": []
+                    },
+                    {
+                        "APIKeyAuth": []
+                    }
+                ],
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Track number",
+                        "name": "track_number",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "orders",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/wb-test_internal_models.Order"
+                            }
+                        }
+                    },
+                    "401": {
+                        "description": "missing, expired or invalid token",
+                        "schema": {
+                            "$ref": "#/definitions/wb-test_pkg_utils_http-utils.ErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "missing role or scope",
+                        "schema": {
+                            "$ref": "#/definitions/wb-test_pkg_utils_http-utils.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "order not found",
+                        "schema": {
+                            "$ref": "#/definitions/wb-test_pkg_utils_http-utils.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "internal server error",
+                        "schema": {
+                            "$ref": "#/definitions/wb-test_pkg_utils_http-utils.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/orders/by-transaction/{transaction}": {
+            "get": {
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Orders"
+                ],
+                "summary": "Get orders by payment transaction",
+                "security": [
+                    {
+                        "BearerAuth": []
+                    },
+                    {
+                        "APIKeyAuth": []
+                    }
+                ],
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Payment transaction",
+                        "name": "transaction",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "orders",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/wb-test_internal_models.Order"
+                            }
+                        }
+                    },
+                    "401": {
+                        "description": "missing, expired or invalid token",
+                        "schema": {
+                            "$ref": "#/definitions/wb-test_pkg_utils_http-utils.ErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "missing role or scope",
+                        "schema": {
+                            "$ref": "#/definitions/wb-test_pkg_utils_http-utils.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "order not found",
+                        "schema": {
+                            "$ref": "#/definitions/wb-test_pkg_utils_http-utils.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "internal server error",
+                        "schema": {
+                            "$ref": "#/definitions/wb-test_pkg_utils_http-utils.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/orders/{order_uid}": {
+            "get": {
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Orders"
+                ],
+                "summary": "Get order by UID",
+                "security": [
+                    {
+                        "BearerAuth": []
+                    },
+                    {
+                        "APIKeyAuth": []
+                    }
+                ],
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Order UID",
+                        "name": "order_uid",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "order",
+                        "schema": {
+                            "$ref": "#/definitions/wb-test_internal_models.Order"
+                        },
+                        "headers": {
+                            "X-Order-Source": {
+                                "type": "string",
+                                "description": "cache or database"
+                            }
+                        }
+                    },
+                    "401": {
+                        "description": "missing, expired or invalid token",
+                        "schema": {
+                            "$ref": "#/definitions/wb-test_pkg_utils_http-utils.ErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "missing role or scope",
+                        "schema": {
+                            "$ref": "#/definitions/wb-test_pkg_utils_http-utils.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "order not found",
+                        "schema": {
+                            "$ref": "#/definitions/wb-test_pkg_utils_http-utils.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "internal server error",
+                        "schema": {
+                            "$ref": "#/definitions/wb-test_pkg_utils_http-utils.ErrorResponse"
+                        }
+                    }
+                }
+            },
+            "put": {
+                "description": "Replaces the order with its delivery, payment and items. A non-zero version must match the stored one.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Orders"
+                ],
+                "summary": "Update or create order",
+                "security": [
+                    {
+                        "BearerAuth": []
+                    },
+                    {
+                        "APIKeyAuth": []
+                    }
+                ],
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Order UID",
+                        "name": "order_uid",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Order",
+                        "name": "order",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/wb-test_internal_models.Order"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "updated order",
+                        "schema": {
+                            "$ref": "#/definitions/wb-test_internal_models.Order"
+                        }
+                    },
+                    "400": {
+                        "description": "invalid request body",
+                        "schema": {
+                            "$ref": "#/definitions/wb-test_pkg_utils_http-utils.ErrorResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "missing, expired or invalid token",
+                        "schema": {
+                            "$ref": "#/definitions/wb-test_pkg_utils_http-utils.ErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "missing role or scope",
+                        "schema": {
+                            "$ref": "#/definitions/wb-test_pkg_utils_http-utils.ErrorResponse"
+                        }
+                    },
+                    "409": {
+                        "description": "order version mismatch",
+                        "schema": {
+                            "$ref": "#/definitions/wb-test_pkg_utils_http-utils.ErrorResponse"
+                        }
+                    },
+                    "422": {
+                        "description": "invalid order",
+                        "schema": {
+                            "$ref": "#/definitions/wb-test_pkg_utils_http-utils.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "internal server error",
+                        "schema": {
+                            "$ref": "#/definitions/wb-test_pkg_utils_http-utils.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/orders/{order_uid}/history": {
+            "get": {
+                "description": "Lists stored versions of the order. When from and to are set the field-level diff between them is included.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Orders"
+                ],
+                "summary": "Get order history",
+                "security": [
+                    {
+                        "BearerAuth": []
+                    },
+                    {
+                        "APIKeyAuth": []
+                    }
+                ],
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Order UID",
+                        "name": "order_uid",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Version to diff from",
+                        "name": "from",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Version to diff to",
+                        "name": "to",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "order history",
+                        "schema": {
+                            "$ref": "#/definitions/wb-test_internal_models.OrderHistory"
+                        }
+                    },
+                    "400": {
+                        "description": "invalid version",
+                        "schema": {
+                            "$ref": "#/definitions/wb-test_pkg_utils_http-utils.ErrorResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "missing, expired or invalid token",
+                        "schema": {
+                            "$ref": "#/definitions/wb-test_pkg_utils_http-utils.ErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "missing role or scope",
+                        "schema": {
+                            "$ref": "#/definitions/wb-test_pkg_utils_http-utils.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "order or version not found",
+                        "schema": {
+                            "$ref": "#/definitions/wb-test_pkg_utils_http-utils.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "internal server error",
+                        "schema": {
+                            "$ref": "#/definitions/wb-test_pkg_utils_http-utils.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        }
+    },
+    "definitions": {
+        "wb-test_internal_models.APIKey": {
+            "type": "object",
+            "properties": {
+                "created_at": {
+                    "type": "string"
+                },
+                "expires_at": {
+                    "type": "string"
+                },
+                "id": {
+                    "type": "integer"
+                },
+                "last_used_at": {
+                    "type": "string"
+                },
+                "name": {
+                    "type": "string"
+                },
+                "prefix": {
+                    "type": "string"
+                },
+                "revoked_at": {
+                    "type": "string"
+                },
+                "scopes": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                }
+            }
+        },
+        "wb-test_internal_models.CreateAPIKeyRequest": {
+            "type": "object",
+            "properties": {
+                "expires_at": {
+                    "type": "string"
+                },
+                "name": {
+                    "type": "string"
+                },
+                "scopes": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                }
+            }
+        },
+        "wb-test_internal_models.CreatedAPIKey": {
+            "type": "object",
+            "properties": {
+                "created_at": {
+                    "type": "string"
+                },
+                "expires_at": {
+                    "type": "string"
+                },
+                "id": {
+                    "type": "integer"
+                },
+                "key": {
+                    "type": "string"
+                },
+                "last_used_at": {
+                    "type": "string"
+                },
+                "name": {
+                    "type": "string"
+                },
+                "prefix": {
+                    "type": "string"
+                },
+                "revoked_at": {
+                    "type": "string"
+                },
+                "scopes": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                }
+            }
+        },
+        "wb-test_internal_models.Delivery": {
+            "type": "object",
+            "properties": {
+                "address": {
+                    "type": "string"
+                },
+                "city": {
+                    "type": "string"
+                },
+                "email": {
+                    "type": "string"
+                },
+                "name": {
+                    "type": "string"
+                },
+                "phone": {
+                    "type": "string"
+                },
+                "region": {
+                    "type": "string"
+                },
+                "zip": {
+                    "type": "string"
+                }
+            }
+        },
+        "wb-test_internal_models.FieldChange": {
+            "type": "object",
+            "properties": {
+                "field": {
+                    "type": "string"
+                },
+                "from": {},
+                "to": {}
+            }
+        },
+        "wb-test_internal_models.Item": {
+            "type": "object",
+            "properties": {
+                "brand": {
+                    "type": "string"
+                },
+                "chrt_id": {
+                    "type": "integer"
+                },
+                "name": {
+                    "type": "string"
+                },
+                "nm_id": {
+                    "type": "integer"
+                },
+                "price": {
+                    "type": "integer"
+                },
+                "rid": {
+                    "type": "string"
+                },
+                "sale": {
+                    "type": "integer"
+                },
+                "size": {
+                    "type": "string"
+                },
+                "status": {
+                    "type": "integer"
+                },
+                "total_price": {
+                    "type": "integer"
+                },
+                "track_number": {
+                    "type": "string"
+                }
+            }
+        },
+        "wb-test_internal_models.LoginRequest": {
+            "type": "object",
+            "properties": {
+                "password": {
+                    "type": "string"
+                },
+                "username": {
+                    "type": "string"
+                }
+            }
+        },
+        "wb-test_internal_models.Order": {
+            "type": "object",
+            "properties": {
+                "customer_id": {
+                    "type": "string"
+                },
+                "date_created": {
+                    "type": "string"
+                },
+                "delivery": {
+                    "$ref": "#/definitions/wb-test_internal_models.Delivery"
+                },
+                "enry": {
+                    "type": "string"
+                },
+                "internal_signature": {
+                    "type": "string"
+                },
+                "items": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/wb-test_internal_models.Item"
+                    }
+                },
+                "locale": {
+                    "type": "string"
+                },
+                "oof_shard": {
+                    "type": "string"
+                },
+                "order_uid": {
+                    "type": "string"
+                },
+                "payment": {
+                    "$ref": "#/definitions/wb-test_internal_models.Payment"
+                },
+                "shardkey": {
+                    "type": "string"
+                },
+                "sm_id": {
+                    "type": "integer"
+                },
+                "track_number": {
+                    "type": "string"
+                },
+                "version": {
+                    "type": "integer"
+                }
+            }
+        },
+        "wb-test_internal_models.OrderDiff": {
+            "type": "object",
+            "properties": {
+                "changes": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/wb-test_internal_models.FieldChange"
+                    }
+                },
+                "from": {
+                    "type": "integer"
+                },
+                "to": {
+                    "type": "integer"
+                }
+            }
+        },
+        "wb-test_internal_models.OrderHistory": {
+            "type": "object",
+            "properties": {
+                "diff": {
+                    "$ref": "#/definitions/wb-test_internal_models.OrderDiff"
+                },
+                "order_uid": {
+                    "type": "string"
+                },
+                "versions": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/wb-test_internal_models.OrderVersion"
+                    }
+                }
+            }
+        },
+        "wb-test_internal_models.OrderPage": {
+            "type": "object",
+            "properties": {
+                "next_cursor": {
+                    "type": "string"
+                },
+                "orders": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/wb-test_internal_models.Order"
+                    }
+                }
+            }
+        },
+        "wb-test_internal_models.OrderVersion": {
+            "type": "object",
+            "properties": {
+                "created_at": {
+                    "type": "string"
+                },
+                "order": {
+                    "$ref": "#/definitions/wb-test_internal_models.Order"
+                },
+                "source": {
+                    "type": "string"
+                },
+                "version": {
+                    "type": "integer"
+                }
+            }
+        },
+        "wb-test_internal_models.Payment": {
+            "type": "object",
+            "properties": {
+                "amount": {
+                    "type": "integer"
+                },
+                "bank": {
+                    "type": "string"
+                },
+                "currency": {
+                    "type": "string"
+                },
+                "custom_fee": {
+                    "type": "integer"
+                },
+                "delivery_cost": {
+                    "type": "integer"
+                },
+                "goods_total": {
+                    "type": "integer"
+                },
+                "payment_dt": {
+                    "type": "integer"
+                },
+                "provider": {
+                    "type": "string"
+                },
+                "request_id": {
+                    "type": "string"
+                },
+                "transaction": {
+                    "type": "string"
+                }
+            }
+        },
+        "wb-test_internal_models.RefreshRequest": {
+            "type": "object",
+            "properties": {
+                "refresh_token": {
+                    "type": "string"
+                }
+            }
+        },
+        "wb-test_internal_models.TokenPair": {
+            "type": "object",
+            "properties": {
+                "access_token": {
+                    "type": "string"
+                },
+                "expires_in": {
+                    "description": "ExpiresIn is the access token lifetime in seconds",
+                    "type": "integer"
+                },
+                "refresh_token": {
+                    "type": "string"
+                },
+                "token_type": {
+                    "type": "string"
+                }
+            }
+        },
+        "wb-test_pkg_broker.DeadLetter": {
+            "type": "object",
+            "properties": {
+                "attempts": {
+                    "type": "integer"
+                },
+                "data": {
+                    "type": "string",
+                    "format": "base64"
+                },
+                "error": {
+                    "type": "string"
+                },
+                "failed_at": {
+                    "type": "string"
+                },
+                "original_subject": {
+                    "type": "string"
+                },
+                "reason": {
+                    "type": "string"
+                },
+                "sequence": {
+                    "type": "integer"
+                },
+                "subject": {
+                    "type": "string"
+                }
+            }
+        },
         "wb-test_pkg_utils_http-utils.ErrorDetail": {
             "type": "object",
             "properties": {
@@ -86,6 +1512,63 @@ const docTemplate = `{
                     "type": "string"
                 }
             }
+        },
+        "wb-test_pkg_utils_jwt.JWK": {
+            "type": "object",
+            "properties": {
+                "alg": {
+                    "type": "string"
+                },
+                "crv": {
+                    "type": "string"
+                },
+                "e": {
+                    "type": "string"
+                },
+                "kid": {
+                    "type": "string"
+                },
+                "kty": {
+                    "type": "string"
+                },
+                "n": {
+                    "type": "string"
+                },
+                "use": {
+                    "type": "string"
+                },
+                "x": {
+                    "type": "string"
+                },
+                "y": {
+                    "type": "string"
+                }
+            }
+        },
+        "wb-test_pkg_utils_jwt.JWKSet": {
+            "type": "object",
+            "properties": {
+                "keys": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/wb-test_pkg_utils_jwt.JWK"
+                    }
+                }
+            }
+        }
+    },
+    "securityDefinitions": {
+        "APIKeyAuth": {
+            "description": "API key of a service caller",
+            "type": "apiKey",
+            "name": "X-API-Key",
+            "in": "header"
+        },
+        "BearerAuth": {
+            "description": "Bearer token: \"Bearer \u003cjwt\u003e\"",
+            "type": "apiKey",
+            "name": "Authorization",
+            "in": "header"
         }
     }
 }`
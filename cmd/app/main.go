@@ -13,20 +13,47 @@ import (
 	ordercache "wb-test/internal/cache/order"
 	orderconsumer "wb-test/internal/consumers/order"
 	handler "wb-test/internal/handlers"
+	apikeyservice "wb-test/internal/service/apikey"
+	authservice "wb-test/internal/service/auth"
 	orderservice "wb-test/internal/service/order"
+	userservice "wb-test/internal/service/user"
+	apikeystorage "wb-test/internal/storage/apikey"
 	orderstorage "wb-test/internal/storage/order"
+	tokenstorage "wb-test/internal/storage/token"
+	userstorage "wb-test/internal/storage/user"
 	"wb-test/pkg/broker"
 	"wb-test/pkg/cache"
 	"wb-test/pkg/config"
 	"wb-test/pkg/db"
 	"wb-test/pkg/logger"
+	"wb-test/pkg/metrics"
+	"wb-test/pkg/tracing"
+	"wb-test/pkg/utils"
+	"wb-test/pkg/utils/jwt"
+
+	"github.com/prometheus/client_golang/prometheus"
 )
 
 //	@title			WB Test
 //	@version		1.0
 //	@description	Документация сервиса заказа
 
+//	@securityDefinitions.apikey	BearerAuth
+//	@in							header
+//	@name						Authorization
+//	@description				Bearer token: "Bearer <jwt>"
+
+//	@securityDefinitions.apikey	APIKeyAuth
+//	@in							header
+//	@name						X-API-Key
+//	@description				API key of a service caller
+
 func main() {
+	// Subcommands manage the app instead of serving
+	if len(os.Args) > 1 && os.Args[1] == "user" {
+		os.Exit(runUserCommand(os.Args[2:]))
+	}
+
 	// Load config first
 	cfg, err := config.Load()
 	if err != nil {
@@ -40,8 +67,30 @@ func main() {
 
 	slog.Info("Config and logger initialized")
 
+	// Load JWT signing keys, refuse to start without them outside dev mode
+	tokenKeys, err := jwt.LoadKeySet(cfg.Auth)
+	if err != nil {
+		log.Error("Failed to load JWT keys", "error", err)
+		panic(err)
+	}
+
 	ctx := context.Background()
 
+	// Initialize tracing before the clients so they pick up the tracer provider
+	shutdownTracing, err := tracing.Init(ctx, cfg.Tracing)
+	if err != nil {
+		log.Error("Failed to initialize tracing", "error", err)
+		panic(err)
+	}
+	defer func() {
+		shutdownCtx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+		if err := shutdownTracing(shutdownCtx); err != nil {
+			log.Error("Failed to flush traces", "error", err)
+		}
+	}()
+	log.Info("Tracing initialized", "exporter", cfg.Tracing.Exporter)
+
 	// Initialize database
 	db, err := db.NewPostgres(ctx, cfg.Database.DSN)
 	if err != nil {
@@ -50,6 +99,7 @@ func main() {
 	}
 	defer db.Close()
 	log.Info("Database connected successfully")
+	prometheus.MustRegister(metrics.NewPoolCollector(db.Pool()))
 
 	// Initialize cache
 	cache, err := cache.NewRedis(ctx, cfg.Redis.Addr, cfg.Redis.Password, cfg.Redis.DB)
@@ -67,29 +117,75 @@ func main() {
 		panic(err)
 	}
 	defer broker.Close()
-	log.Info("NATS connected successfully")
+	if cfg.NATS.JetStream.Enabled {
+		if err := broker.EnableJetStream(); err != nil {
+			log.Error("Failed to enable JetStream", "error", err)
+			panic(err)
+		}
+	}
+	log.Info("NATS connected successfully", "jetstream", cfg.NATS.JetStream.Enabled)
 
 	// Initialize order repo
 	orderRepo := orderstorage.NewOrderRepo(db)
 	log.Info("Order repo initialized successfully")
 
 	// Initialize order cache
-	orderCache := ordercache.NewOrderCache(cache)
-	log.Info("Order cache initialized successfully")
+	redisOrderCache := ordercache.NewOrderCache(cache)
+	var orderCache orderservice.OrderCache = redisOrderCache
+	if cfg.LRU.Enabled {
+		// Keep hot orders in process memory in front of Redis
+		orderCache = ordercache.NewLRUOrderCache(redisOrderCache, cfg.LRU.Size, cfg.LRU.TTL)
+	}
+	log.Info("Order cache initialized successfully", "lru_enabled", cfg.LRU.Enabled)
 
 	// Initialize order service
-	orderService := orderservice.NewOrderService(orderRepo, orderCache)
+	orderService := orderservice.NewOrderService(orderRepo, orderCache, utils.Backoff{
+		MaxAttempts:  cfg.Retry.MaxAttempts,
+		InitialDelay: cfg.Retry.InitialDelay,
+		MaxDelay:     cfg.Retry.MaxDelay,
+		Multiplier:   cfg.Retry.Multiplier,
+	})
 	log.Info("Order service initialized successfully")
 
+	// Restore cache from database before serving requests
+	if cfg.Warmup.Enabled {
+		start := time.Now()
+		restored, err := orderService.WarmupCache(ctx, cfg.Warmup.Limit, cfg.Warmup.MaxAge, cfg.Warmup.BatchSize)
+		if err != nil {
+			log.Error("Failed to warm up order cache", "error", err, "restored", restored)
+		} else {
+			log.Info("Order cache warmed up", "restored", restored, "duration", time.Since(start))
+		}
+	}
+
+	// Initialize users and refresh token store, revoked access tokens are rejected on parse
+	tokenStore := tokenstorage.NewTokenStore(cache)
+	tokenKeys.SetDenylist(tokenStore)
+	userService := userservice.NewUserService(userstorage.NewUserRepo(db), cfg.Auth)
+	authService := authservice.NewAuthService(tokenKeys, tokenStore, userService, cfg.Auth.AccessTTL, cfg.Auth.RefreshTTL)
+	log.Info("Auth service initialized successfully")
+
+	// Initialize API keys of service-to-service callers
+	apiKeyService := apikeyservice.NewAPIKeyService(apikeystorage.NewAPIKeyRepo(db))
+
+	// Initialize dead-letter queue for orders that fail processing
+	deadLetters := broker.DeadLetterQueue(cfg.NATS.DeadLetter.Subject, cfg.NATS.DeadLetter.Stream, cfg.NATS.DeadLetter.MaxAge)
+	// Dead letters are stored in a stream in core NATS mode too, without one
+	// failed messages would be lost
+	if err := deadLetters.Provision(ctx); err != nil {
+		log.Error("Failed to provision dead-letter stream", "error", err)
+		panic(err)
+	}
+
 	// Initialize and start order consumer
-	orderConsumer := orderconsumer.NewOrderConsumer(broker, orderService)
+	orderConsumer := orderconsumer.NewOrderConsumer(broker, orderService, deadLetters, cfg.Consumer, cfg.NATS.JetStream)
 	log.Info("Order consumer initialized successfully")
 
 	// Create context with cancellation for graceful shutdown
 	ctx, cancel := context.WithCancel(context.Background())
 	defer cancel()
 
-	handlers := handler.NewHandler()
+	handlers := handler.NewHandler(orderService, deadLetters, tokenKeys, authService, apiKeyService)
 	router := handler.InitRouter(handlers)
 
 	httpServer := &http.Server{
@@ -97,6 +193,11 @@ func main() {
 		Handler: router,
 	}
 
+	metricsServer := &http.Server{
+		Addr:    fmt.Sprintf(":%s", cfg.Server.MetricsPort),
+		Handler: handler.InitMetricsRouter(),
+	}
+
 	// Start the consumer in a goroutine
 	go func() {
 		if err := orderConsumer.Start(ctx); err != nil {
@@ -116,6 +217,15 @@ func main() {
 		}
 	}()
 
+	// Start metrics server in a goroutine
+	go func() {
+		log.Info("Starting metrics server", "port", cfg.Server.MetricsPort, "addr", metricsServer.Addr)
+		if err := metricsServer.ListenAndServe(); err != nil && !errors.Is(err, http.ErrServerClosed) {
+			log.Error("Metrics server error", "error", err)
+			cancel()
+		}
+	}()
+
 	log.Info("All servers are ready to handle requests")
 
 	stop := make(chan os.Signal, 1)
@@ -144,5 +254,11 @@ func main() {
 		log.Info("HTTP server stopped gracefully")
 	}
 
+	if err := metricsServer.Shutdown(shutdownCtx); err != nil {
+		log.Error("Metrics server shutdown failed", "error", err)
+	} else {
+		log.Info("Metrics server stopped gracefully")
+	}
+
 	log.Info("Application shutdown complete")
 }
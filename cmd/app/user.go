@@ -0,0 +1,102 @@
+package main
+
+import (
+	"bufio"
+	"context"
+	"flag"
+	"fmt"
+	"os"
+	"strings"
+
+	userservice "wb-test/internal/service/user"
+	userstorage "wb-test/internal/storage/user"
+	"wb-test/pkg/config"
+	"wb-test/pkg/db"
+)
+
+const userUsage = `usage:
+  app user create -username NAME [-password PASSWORD] [-roles ROLE,...] [-scopes SCOPE,...]
+  app user roles -username NAME [-roles ROLE,...] [-scopes SCOPE,...]
+
+Without -password the password is read from the first line of stdin.
+roles replaces the roles and scopes of the user.`
+
+// runUserCommand creates users and assigns their roles, it returns the exit code
+func runUserCommand(args []string) int {
+	if len(args) == 0 {
+		fmt.Fprintln(os.Stderr, userUsage)
+		return 2
+	}
+
+	fs := flag.NewFlagSet("user "+args[0], flag.ContinueOnError)
+	var (
+		username = fs.String("username", "", "User name")
+		password = fs.String("password", "", "Password, read from stdin when empty")
+		roles    = fs.String("roles", "", "Comma separated roles, e.g. admin")
+		scopes   = fs.String("scopes", "", "Comma separated scopes, e.g. orders:read,orders:write")
+	)
+	if err := fs.Parse(args[1:]); err != nil {
+		return 2
+	}
+	if *username == "" {
+		fmt.Fprintln(os.Stderr, "-username is required")
+		return 2
+	}
+
+	cfg, err := config.Load()
+	if err != nil {
+		fmt.Fprintln(os.Stderr, "failed to load config:", err)
+		return 1
+	}
+
+	db, err := db.NewPostgres(context.Background(), cfg.Database.DSN)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, "failed to connect to database:", err)
+		return 1
+	}
+	defer db.Close()
+
+	users := userservice.NewUserService(userstorage.NewUserRepo(db), cfg.Auth)
+
+	switch args[0] {
+	case "create":
+		if *password == "" {
+			// Keep the password out of shell history and the process list
+			line, err := bufio.NewReader(os.Stdin).ReadString('\n')
+			if err != nil && line == "" {
+				fmt.Fprintln(os.Stderr, "failed to read password:", err)
+				return 1
+			}
+			*password = strings.TrimRight(line, "\r\n")
+		}
+
+		user, err := users.CreateUser(*username, *password, splitList(*roles), splitList(*scopes))
+		if err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			return 1
+		}
+		fmt.Printf("created user %q with id %d\n", user.Username, user.ID)
+	case "roles":
+		if err := users.SetUserRoles(*username, splitList(*roles), splitList(*scopes)); err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			return 1
+		}
+		fmt.Printf("updated roles of user %q\n", *username)
+	default:
+		fmt.Fprintln(os.Stderr, userUsage)
+		return 2
+	}
+
+	return 0
+}
+
+// splitList splits a comma separated flag value, dropping empty entries
+func splitList(value string) []string {
+	var values []string
+	for _, v := range strings.Split(value, ",") {
+		if v = strings.TrimSpace(v); v != "" {
+			values = append(values, v)
+		}
+	}
+	return values
+}
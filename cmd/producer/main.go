@@ -14,6 +14,7 @@ import (
 	"wb-test/pkg/broker"
 	"wb-test/pkg/config"
 	"wb-test/pkg/logger"
+	"wb-test/pkg/tracing"
 )
 
 const (
@@ -42,6 +43,20 @@ func main() {
 
 	ctx := context.Background()
 
+	// Each published order starts a trace the consumer continues
+	shutdownTracing, err := tracing.Init(ctx, cfg.Tracing)
+	if err != nil {
+		log.Error("Failed to initialize tracing", "error", err)
+		os.Exit(1)
+	}
+	defer shutdownTracing(context.Background())
+
+	streamCfg := broker.StreamConfig{
+		Name:     cfg.NATS.JetStream.Stream,
+		Subjects: cfg.NATS.JetStream.Subjects,
+		MaxAge:   cfg.NATS.JetStream.StreamMaxAge,
+	}
+
 	// Initialize NATS broker
 	broker, err := broker.NewNATS(ctx, cfg.NATS.URL)
 	if err != nil {
@@ -50,7 +65,21 @@ func main() {
 	}
 	defer broker.Close()
 
-	log.Info("Connected to NATS", "url", cfg.NATS.URL)
+	// Publish through JetStream so every order is acknowledged by the stream
+	if cfg.NATS.JetStream.Enabled {
+		if err := broker.EnableJetStream(); err != nil {
+			log.Error("Failed to enable JetStream", "error", err)
+			os.Exit(1)
+		}
+		if cfg.NATS.JetStream.AutoProvision {
+			if _, err := broker.EnsureStream(ctx, streamCfg); err != nil {
+				log.Error("Failed to provision stream", "error", err)
+				os.Exit(1)
+			}
+		}
+	}
+
+	log.Info("Connected to NATS", "url", cfg.NATS.URL, "jetstream", cfg.NATS.JetStream.Enabled)
 
 	// Create context with cancellation for graceful shutdown
 	ctx, cancel := context.WithCancel(context.Background())
@@ -85,7 +114,7 @@ func main() {
 		case <-ticker.C:
 			order := generateSampleOrder(i)
 
-			if err := broker.PublishOrder(*subject, order); err != nil {
+			if err := broker.PublishOrder(ctx, *subject, order); err != nil {
 				log.Error("Failed to publish order", "error", err, "order_uid", order.OrderUID)
 				continue
 			}
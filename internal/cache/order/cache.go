@@ -9,10 +9,17 @@ import (
 
 	"wb-test/internal/models"
 	"wb-test/pkg/cache"
+	"wb-test/pkg/metrics"
+	"wb-test/pkg/tracing"
 
 	"github.com/redis/go-redis/v9"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
 )
 
+var tracer = otel.Tracer("wb-test/internal/cache/order")
+
 type orderCache struct {
 	client *cache.RedisClient
 }
@@ -21,31 +28,37 @@ func NewOrderCache(client *cache.RedisClient) *orderCache {
 	return &orderCache{client: client}
 }
 
-func (c *orderCache) GetOrder(orderUID string) (*models.Order, error) {
-	ctx := context.Background()
+func (c *orderCache) GetOrder(ctx context.Context, orderUID string) (*models.Order, error) {
 	key := fmt.Sprintf("order:%s", orderUID)
 
 	data, err := c.client.Client().Get(ctx, key).Bytes()
 	if err != nil {
 		if err == redis.Nil {
-			slog.Error("Order not found in cache", "order_uid", orderUID)
+			metrics.CacheRequests.WithLabelValues("redis", metrics.CacheMiss).Inc()
+			slog.Debug("Order not found in cache", "order_uid", orderUID)
 			return nil, nil
 		}
+		c.failed("get")
 		slog.Error("Failed to get order from cache", "error", err)
 		return nil, err
 	}
 
 	var order models.Order
 	if err := json.Unmarshal(data, &order); err != nil {
+		c.failed("get")
 		return nil, fmt.Errorf("failed to unmarshal order from cache: %w", err)
 	}
 
+	metrics.CacheRequests.WithLabelValues("redis", metrics.CacheHit).Inc()
+
 	slog.Info("Order retrieved from cache", "order_uid", orderUID)
 	return &order, nil
 }
 
-func (c *orderCache) SetOrder(orderUID string, order *models.Order) error {
-	ctx := context.Background()
+func (c *orderCache) SetOrder(ctx context.Context, orderUID string, order *models.Order) (err error) {
+	ctx, span := tracer.Start(ctx, "orderCache.SetOrder", trace.WithAttributes(attribute.String("order.uid", orderUID)))
+	defer func() { tracing.End(span, err) }()
+
 	key := fmt.Sprintf("order:%s", orderUID)
 
 	data, err := json.Marshal(order)
@@ -56,6 +69,7 @@ func (c *orderCache) SetOrder(orderUID string, order *models.Order) error {
 	// Set with TTL (24 hours)
 	err = c.client.Client().Set(ctx, key, data, 24*time.Hour).Err()
 	if err != nil {
+		metrics.CacheErrors.WithLabelValues("set").Inc()
 		return fmt.Errorf("failed to set order in cache: %w", err)
 	}
 
@@ -63,15 +77,44 @@ func (c *orderCache) SetOrder(orderUID string, order *models.Order) error {
 	return nil
 }
 
-func (c *orderCache) DeleteOrder(orderUID string) error {
-	ctx := context.Background()
+func (c *orderCache) DeleteOrder(ctx context.Context, orderUID string) error {
 	key := fmt.Sprintf("order:%s", orderUID)
 
 	err := c.client.Client().Del(ctx, key).Err()
 	if err != nil {
+		metrics.CacheErrors.WithLabelValues("delete").Inc()
 		return fmt.Errorf("failed to delete order from cache: %w", err)
 	}
 
 	slog.Info("Order deleted from cache", "order_uid", orderUID)
 	return nil
 }
+
+// failed counts a failed lookup both as a request result and as an error
+func (c *orderCache) failed(operation string) {
+	metrics.CacheRequests.WithLabelValues("redis", metrics.CacheError).Inc()
+	metrics.CacheErrors.WithLabelValues(operation).Inc()
+}
+
+// SetOrders stores a batch of orders in a single pipelined round-trip
+func (c *orderCache) SetOrders(ctx context.Context, orders []*models.Order) (err error) {
+	ctx, span := tracer.Start(ctx, "orderCache.SetOrders", trace.WithAttributes(attribute.Int("order.count", len(orders))))
+	defer func() { tracing.End(span, err) }()
+
+	pipe := c.client.Client().Pipeline()
+	for _, order := range orders {
+		data, err := json.Marshal(order)
+		if err != nil {
+			return fmt.Errorf("failed to marshal order for cache: %w", err)
+		}
+		pipe.Set(ctx, fmt.Sprintf("order:%s", order.OrderUID), data, 24*time.Hour)
+	}
+
+	if _, err := pipe.Exec(ctx); err != nil {
+		metrics.CacheErrors.WithLabelValues("set_many").Inc()
+		return fmt.Errorf("failed to set orders in cache: %w", err)
+	}
+
+	slog.Debug("Orders saved to cache", "count", len(orders))
+	return nil
+}
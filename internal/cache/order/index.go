@@ -0,0 +1,125 @@
+package order
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"sort"
+	"time"
+
+	"wb-test/internal/models"
+)
+
+// indexKey is the Redis set of order UIDs sharing a secondary key value,
+// members point to order:{uid} entries
+func indexKey(lookup models.OrderLookup, value string) string {
+	return fmt.Sprintf("order-index:%s:%s", lookup, value)
+}
+
+// GetIndexedOrders returns the orders stored under a secondary key. ok is false
+// when the index is missing or no longer matches the cached orders, the caller
+// should then load the orders from the database and store them again.
+func (c *orderCache) GetIndexedOrders(ctx context.Context, lookup models.OrderLookup, value string) ([]*models.Order, bool, error) {
+	uids, err := c.client.Client().SMembers(ctx, indexKey(lookup, value)).Result()
+	if err != nil {
+		return nil, false, fmt.Errorf("failed to get order index from cache: %w", err)
+	}
+	if len(uids) == 0 {
+		slog.Debug("Order index not found in cache", "lookup", lookup, "value", value)
+		return nil, false, nil
+	}
+
+	keys := make([]string, len(uids))
+	for i, uid := range uids {
+		keys[i] = fmt.Sprintf("order:%s", uid)
+	}
+	values, err := c.client.Client().MGet(ctx, keys...).Result()
+	if err != nil {
+		return nil, false, fmt.Errorf("failed to get indexed orders from cache: %w", err)
+	}
+
+	orders := make([]*models.Order, 0, len(values))
+	for _, v := range values {
+		data, ok := v.(string)
+		if !ok {
+			// An indexed order expired or was invalidated
+			return nil, false, nil
+		}
+		var order models.Order
+		if err := json.Unmarshal([]byte(data), &order); err != nil {
+			return nil, false, fmt.Errorf("failed to unmarshal order from cache: %w", err)
+		}
+		if order.LookupValue(lookup) != value {
+			// The order was updated away from this key
+			return nil, false, nil
+		}
+		orders = append(orders, &order)
+	}
+
+	sortOrders(orders)
+	slog.Info("Orders retrieved from cache by index", "lookup", lookup, "value", value, "count", len(orders))
+	return orders, true, nil
+}
+
+// SetIndexedOrders stores the orders and replaces the secondary key index
+// with exactly their UIDs
+func (c *orderCache) SetIndexedOrders(ctx context.Context, lookup models.OrderLookup, value string, orders []*models.Order) error {
+	if len(orders) == 0 {
+		return nil
+	}
+
+	key := indexKey(lookup, value)
+
+	pipe := c.client.Client().TxPipeline()
+	uids := make([]any, len(orders))
+	for i, order := range orders {
+		data, err := json.Marshal(order)
+		if err != nil {
+			return fmt.Errorf("failed to marshal order for cache: %w", err)
+		}
+		pipe.Set(ctx, fmt.Sprintf("order:%s", order.OrderUID), data, 24*time.Hour)
+		uids[i] = order.OrderUID
+	}
+	pipe.Del(ctx, key)
+	pipe.SAdd(ctx, key, uids...)
+	pipe.Expire(ctx, key, 24*time.Hour)
+
+	if _, err := pipe.Exec(ctx); err != nil {
+		return fmt.Errorf("failed to set order index in cache: %w", err)
+	}
+
+	slog.Debug("Order index saved to cache", "lookup", lookup, "value", value, "count", len(orders))
+	return nil
+}
+
+// DeleteOrderIndexes drops the secondary key indexes the orders belong to, so
+// lookups pick up newly written orders
+func (c *orderCache) DeleteOrderIndexes(ctx context.Context, orders []*models.Order) error {
+	var keys []string
+	for _, order := range orders {
+		for _, lookup := range models.OrderLookups {
+			if value := order.LookupValue(lookup); value != "" {
+				keys = append(keys, indexKey(lookup, value))
+			}
+		}
+	}
+	if len(keys) == 0 {
+		return nil
+	}
+
+	if err := c.client.Client().Del(ctx, keys...).Err(); err != nil {
+		return fmt.Errorf("failed to delete order indexes from cache: %w", err)
+	}
+	return nil
+}
+
+// sortOrders orders a lookup result like the database listing, newest first
+func sortOrders(orders []*models.Order) {
+	sort.Slice(orders, func(i, j int) bool {
+		if !orders[i].DateCreated.Equal(orders[j].DateCreated) {
+			return orders[i].DateCreated.After(orders[j].DateCreated)
+		}
+		return orders[i].OrderUID > orders[j].OrderUID
+	})
+}
@@ -0,0 +1,170 @@
+package order
+
+import (
+	"container/list"
+	"context"
+	"log/slog"
+	"sync"
+	"time"
+
+	"wb-test/internal/models"
+	"wb-test/pkg/metrics"
+)
+
+// OrderCache is the cache tier the LRU layers over (usually the Redis cache)
+type OrderCache interface {
+	GetOrder(ctx context.Context, orderUID string) (*models.Order, error)
+	SetOrder(ctx context.Context, orderUID string, order *models.Order) error
+	SetOrders(ctx context.Context, orders []*models.Order) error
+	DeleteOrder(ctx context.Context, orderUID string) error
+	GetIndexedOrders(ctx context.Context, lookup models.OrderLookup, value string) ([]*models.Order, bool, error)
+	SetIndexedOrders(ctx context.Context, lookup models.OrderLookup, value string, orders []*models.Order) error
+	DeleteOrderIndexes(ctx context.Context, orders []*models.Order) error
+}
+
+type lruEntry struct {
+	orderUID  string
+	order     *models.Order
+	expiresAt time.Time
+}
+
+// lruOrderCache is a size- and TTL-bounded in-memory cache in front of another OrderCache
+type lruOrderCache struct {
+	next    OrderCache
+	maxSize int
+	ttl     time.Duration
+
+	mu      sync.Mutex
+	ll      *list.List
+	entries map[string]*list.Element
+}
+
+func NewLRUOrderCache(next OrderCache, maxSize int, ttl time.Duration) *lruOrderCache {
+	return &lruOrderCache{
+		next:    next,
+		maxSize: maxSize,
+		ttl:     ttl,
+		ll:      list.New(),
+		entries: make(map[string]*list.Element),
+	}
+}
+
+func (c *lruOrderCache) GetOrder(ctx context.Context, orderUID string) (*models.Order, error) {
+	if order, ok := c.get(orderUID); ok {
+		metrics.CacheRequests.WithLabelValues("lru", metrics.CacheHit).Inc()
+		return order, nil
+	}
+	metrics.CacheRequests.WithLabelValues("lru", metrics.CacheMiss).Inc()
+
+	order, err := c.next.GetOrder(ctx, orderUID)
+	if err != nil || order == nil {
+		return order, err
+	}
+
+	c.add(orderUID, order)
+	return order, nil
+}
+
+func (c *lruOrderCache) SetOrder(ctx context.Context, orderUID string, order *models.Order) error {
+	if err := c.next.SetOrder(ctx, orderUID, order); err != nil {
+		return err
+	}
+
+	c.add(orderUID, order)
+	return nil
+}
+
+func (c *lruOrderCache) SetOrders(ctx context.Context, orders []*models.Order) error {
+	if err := c.next.SetOrders(ctx, orders); err != nil {
+		return err
+	}
+
+	for _, order := range orders {
+		c.add(order.OrderUID, order)
+	}
+	return nil
+}
+
+func (c *lruOrderCache) DeleteOrder(ctx context.Context, orderUID string) error {
+	c.mu.Lock()
+	if el, ok := c.entries[orderUID]; ok {
+		c.removeElement(el)
+	}
+	c.mu.Unlock()
+
+	return c.next.DeleteOrder(ctx, orderUID)
+}
+
+// GetIndexedOrders is served by the next tier, the local tier keeps no indexes
+func (c *lruOrderCache) GetIndexedOrders(ctx context.Context, lookup models.OrderLookup, value string) ([]*models.Order, bool, error) {
+	return c.next.GetIndexedOrders(ctx, lookup, value)
+}
+
+func (c *lruOrderCache) SetIndexedOrders(ctx context.Context, lookup models.OrderLookup, value string, orders []*models.Order) error {
+	if err := c.next.SetIndexedOrders(ctx, lookup, value, orders); err != nil {
+		return err
+	}
+
+	for _, order := range orders {
+		c.add(order.OrderUID, order)
+	}
+	return nil
+}
+
+func (c *lruOrderCache) DeleteOrderIndexes(ctx context.Context, orders []*models.Order) error {
+	return c.next.DeleteOrderIndexes(ctx, orders)
+}
+
+func (c *lruOrderCache) get(orderUID string) (*models.Order, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	el, ok := c.entries[orderUID]
+	if !ok {
+		return nil, false
+	}
+
+	entry := el.Value.(*lruEntry)
+	if c.ttl > 0 && time.Now().After(entry.expiresAt) {
+		c.removeElement(el)
+		return nil, false
+	}
+
+	c.ll.MoveToFront(el)
+	return entry.order, true
+}
+
+func (c *lruOrderCache) add(orderUID string, order *models.Order) {
+	if c.maxSize <= 0 {
+		return
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	expiresAt := time.Now().Add(c.ttl)
+	if el, ok := c.entries[orderUID]; ok {
+		entry := el.Value.(*lruEntry)
+		entry.order = order
+		entry.expiresAt = expiresAt
+		c.ll.MoveToFront(el)
+		return
+	}
+
+	c.entries[orderUID] = c.ll.PushFront(&lruEntry{
+		orderUID:  orderUID,
+		order:     order,
+		expiresAt: expiresAt,
+	})
+
+	for c.ll.Len() > c.maxSize {
+		c.removeElement(c.ll.Back())
+	}
+}
+
+func (c *lruOrderCache) removeElement(el *list.Element) {
+	entry := el.Value.(*lruEntry)
+	c.ll.Remove(el)
+	delete(c.entries, entry.orderUID)
+	slog.Debug("Order removed from local cache", "order_uid", entry.orderUID)
+}
@@ -0,0 +1,128 @@
+package order
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"time"
+
+	"wb-test/internal/models"
+	"wb-test/pkg/metrics"
+	"wb-test/pkg/utils"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// handleOrderBatched decodes an order and hands it to the batcher. The batch
+// is stored under its own trace, the message trace ends here.
+func (oc *OrderConsumer) handleOrderBatched(_ context.Context, data []byte) error {
+	order, err := decodeOrder(data)
+	if err != nil {
+		return err
+	}
+
+	select {
+	case <-oc.batchDone:
+		return fmt.Errorf("consumer is stopping, order %s not accepted", order.OrderUID)
+	default:
+	}
+
+	select {
+	case oc.batch <- order:
+		return nil
+	case <-oc.batchDone:
+		return fmt.Errorf("consumer is stopping, order %s not accepted", order.OrderUID)
+	}
+}
+
+// startBatcher starts accumulating orders handed over by handleOrderBatched
+func (oc *OrderConsumer) startBatcher() {
+	oc.batch = make(chan *models.Order, oc.cfg.BatchSize)
+	oc.batchStop = make(chan struct{})
+	oc.batchDone = make(chan struct{})
+	go oc.runBatcher()
+}
+
+// stopBatcher flushes the buffered orders and waits for the batcher to exit.
+// Call it only once no handler can hand over orders anymore.
+func (oc *OrderConsumer) stopBatcher() {
+	close(oc.batchStop)
+	<-oc.batchDone
+}
+
+// runBatcher accumulates orders and flushes them when the batch is full or
+// the window since the first buffered order has elapsed
+func (oc *OrderConsumer) runBatcher() {
+	defer close(oc.batchDone)
+
+	buf := make([]*models.Order, 0, oc.cfg.BatchSize)
+	timer := time.NewTimer(oc.cfg.BatchWindow)
+	timer.Stop()
+
+	flush := func(reason string) {
+		if len(buf) == 0 {
+			return
+		}
+		slog.Debug("Flushing order batch", "size", len(buf), "reason", reason)
+		// Orders of a batch come from different traces, the flush starts its own
+		ctx, span := tracer.Start(context.Background(), "OrderConsumer.flushBatch",
+			trace.WithAttributes(attribute.Int("order.count", len(buf)), attribute.String("flush.reason", reason)),
+		)
+		defer span.End()
+
+		start := time.Now()
+		err := oc.service.ProcessOrders(ctx, buf)
+		metrics.ObserveProcessing("batch", start)
+		if err != nil {
+			slog.Error("Failed to process order batch", "error", err, "size", len(buf))
+			span.RecordError(err)
+			span.SetStatus(codes.Error, err.Error())
+			if utils.IsPermanent(err) {
+				// Some order in the batch is bad, store them one by one so
+				// only the bad ones are dead-lettered
+				for _, order := range buf {
+					if err := oc.processOrder(ctx, order); err != nil {
+						oc.deadLetterOrders([]*models.Order{order}, err)
+					}
+				}
+			} else {
+				oc.deadLetterOrders(buf, err)
+			}
+		} else {
+			metrics.MessagesProcessed.WithLabelValues(OrderSubject).Add(float64(len(buf)))
+		}
+		buf = make([]*models.Order, 0, oc.cfg.BatchSize)
+	}
+
+	for {
+		select {
+		case order := <-oc.batch:
+			if len(buf) == 0 {
+				timer.Reset(oc.cfg.BatchWindow)
+			}
+			buf = append(buf, order)
+			if len(buf) >= oc.cfg.BatchSize {
+				timer.Stop()
+				flush("size")
+			}
+		case <-timer.C:
+			flush("window")
+		case <-oc.batchStop:
+			timer.Stop()
+			// Drain whatever is already buffered in the channel
+		drain:
+			for {
+				select {
+				case order := <-oc.batch:
+					buf = append(buf, order)
+				default:
+					break drain
+				}
+			}
+			flush("shutdown")
+			return
+		}
+	}
+}
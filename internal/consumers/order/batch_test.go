@@ -0,0 +1,96 @@
+package order
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"wb-test/pkg/config"
+	"wb-test/pkg/utils"
+)
+
+func newBatchConsumer(service *fakeOrderService, size int, window time.Duration) *OrderConsumer {
+	oc := NewOrderConsumer(nil, service, nil, config.ConsumerConfig{BatchSize: size, BatchWindow: window}, config.JetStreamConfig{})
+	oc.startBatcher()
+	return oc
+}
+
+func TestBatcherFlushesFullBatch(t *testing.T) {
+	service := &fakeOrderService{}
+	oc := newBatchConsumer(service, 3, time.Hour)
+
+	for _, uid := range []string{"a", "b", "c", "d"} {
+		require.NoError(t, oc.handleOrderBatched(context.Background(), orderPayload(t, uid)))
+	}
+
+	// The first three fill the batch, the fourth waits for more
+	assert.Eventually(t, func() bool { return len(service.recordedBatches()) == 1 }, time.Second, 5*time.Millisecond)
+	assert.Equal(t, [][]string{{"a", "b", "c"}}, service.recordedBatches())
+
+	oc.stopBatcher()
+	assert.Equal(t, [][]string{{"a", "b", "c"}, {"d"}}, service.recordedBatches())
+}
+
+func TestBatcherFlushesWhenWindowElapses(t *testing.T) {
+	service := &fakeOrderService{}
+	oc := newBatchConsumer(service, 10, 20*time.Millisecond)
+	defer oc.stopBatcher()
+
+	require.NoError(t, oc.handleOrderBatched(context.Background(), orderPayload(t, "a")))
+	require.NoError(t, oc.handleOrderBatched(context.Background(), orderPayload(t, "b")))
+
+	assert.Eventually(t, func() bool { return len(service.recordedBatches()) == 1 }, time.Second, 5*time.Millisecond)
+	assert.Equal(t, [][]string{{"a", "b"}}, service.recordedBatches())
+}
+
+func TestBatcherFlushesBufferedOrdersOnStop(t *testing.T) {
+	service := &fakeOrderService{}
+	oc := newBatchConsumer(service, 10, time.Hour)
+
+	require.NoError(t, oc.handleOrderBatched(context.Background(), orderPayload(t, "a")))
+	require.NoError(t, oc.handleOrderBatched(context.Background(), orderPayload(t, "b")))
+	oc.stopBatcher()
+
+	assert.Equal(t, [][]string{{"a", "b"}}, service.recordedBatches())
+
+	// Orders handed over after the stop are refused, not silently dropped
+	assert.Error(t, oc.handleOrderBatched(context.Background(), orderPayload(t, "c")))
+}
+
+func TestBatcherFallsBackToSingleWritesOnPermanentError(t *testing.T) {
+	bad := utils.Permanent(errors.New("order b conflicts"))
+	service := &fakeOrderService{
+		batchErr: bad,
+		fail: func(orderUID string) error {
+			if orderUID == "b" {
+				return bad
+			}
+			return nil
+		},
+	}
+	oc := newBatchConsumer(service, 3, time.Hour)
+
+	for _, uid := range []string{"a", "b", "c"} {
+		require.NoError(t, oc.handleOrderBatched(context.Background(), orderPayload(t, uid)))
+	}
+	oc.stopBatcher()
+
+	assert.Equal(t, [][]string{{"a", "b", "c"}}, service.recordedBatches())
+	assert.Equal(t, []string{"a", "b", "c"}, service.recordedSingle())
+}
+
+func TestBatcherDoesNotRetrySingleWritesOnTransientError(t *testing.T) {
+	service := &fakeOrderService{batchErr: errors.New("connection reset")}
+	oc := newBatchConsumer(service, 2, time.Hour)
+
+	require.NoError(t, oc.handleOrderBatched(context.Background(), orderPayload(t, "a")))
+	require.NoError(t, oc.handleOrderBatched(context.Background(), orderPayload(t, "b")))
+	oc.stopBatcher()
+
+	assert.Len(t, service.recordedBatches(), 1)
+	assert.Empty(t, service.recordedSingle())
+}
@@ -4,49 +4,138 @@ import (
 	"context"
 	"fmt"
 	"log/slog"
+	"time"
 
 	"wb-test/internal/models"
 	"wb-test/pkg/broker"
+	"wb-test/pkg/config"
+
+	"github.com/nats-io/nats.go"
+	"go.opentelemetry.io/otel"
 )
 
 const (
-	OrderSubject = "orders.new"
-	QueueGroup   = "order-processors"
+	OrderSubject        = "orders.new"
+	OrderUpdatedSubject = "orders.updated"
+	QueueGroup          = "order-processors"
+
+	// drainTimeout bounds how long shutdown waits for in-flight messages
+	drainTimeout = 10 * time.Second
 )
 
+var tracer = otel.Tracer("wb-test/internal/consumers/order")
+
 type OrderService interface {
-	ProcessOrder(order *models.Order) error
+	ProcessOrder(ctx context.Context, order *models.Order) error
+	ProcessOrders(ctx context.Context, orders []*models.Order) error
+	UpdateOrder(ctx context.Context, order *models.Order) error
+}
+
+// DeadLetterQueue receives messages that failed processing
+type DeadLetterQueue interface {
+	Publish(originalSubject string, data []byte, attempts int, cause error) error
+	PublishConflict(originalSubject string, data []byte, attempts int, cause error) error
 }
 
 type OrderConsumer struct {
 	broker  *broker.NATSClient
 	service OrderService
+	dlq     DeadLetterQueue
+	cfg     config.ConsumerConfig
+	jsCfg   config.JetStreamConfig
+
+	// batch, batchStop and batchDone are only used in batch mode
+	batch     chan *models.Order
+	batchStop chan struct{}
+	batchDone chan struct{}
 }
 
-func NewOrderConsumer(broker *broker.NATSClient, service OrderService) *OrderConsumer {
+// NewOrderConsumer creates an order consumer, dlq may be nil to drop failed messages
+func NewOrderConsumer(broker *broker.NATSClient, service OrderService, dlq DeadLetterQueue, cfg config.ConsumerConfig, jsCfg config.JetStreamConfig) *OrderConsumer {
 	return &OrderConsumer{
 		broker:  broker,
 		service: service,
+		dlq:     dlq,
+		cfg:     cfg,
+		jsCfg:   jsCfg,
 	}
 }
 
 // Start starts the order consumer
 func (oc *OrderConsumer) Start(ctx context.Context) error {
-	slog.Info("Starting order consumer", "subject", OrderSubject, "queue_group", QueueGroup)
+	if oc.jsCfg.Enabled {
+		return oc.startJetStream(ctx)
+	}
+
+	slog.Info("Starting order consumer",
+		"subjects", []string{OrderSubject, OrderUpdatedSubject},
+		"queue_group", QueueGroup,
+		"batch_size", oc.cfg.BatchSize,
+		"batch_window", oc.cfg.BatchWindow,
+	)
+
+	handler := oc.handleOrder
+	if oc.batchMode() {
+		oc.startBatcher()
+		handler = oc.handleOrderBatched
+	}
+
 	// Subscribe to orders with queue group for load balancing
-	sub, err := oc.broker.SubscribeToOrdersWithQueue(OrderSubject, QueueGroup, oc.handleOrder)
+	sub, err := oc.broker.SubscribeToOrdersWithQueue(OrderSubject, QueueGroup, oc.withDeadLetter(OrderSubject, handler))
 	if err != nil {
+		if oc.batchMode() {
+			oc.stopBatcher()
+		}
 		return fmt.Errorf("failed to subscribe to orders: %w", err)
 	}
 
+	// Updates are never batched, they replace a stored order one by one
+	updSub, err := oc.broker.SubscribeToOrdersWithQueue(OrderUpdatedSubject, QueueGroup, oc.withDeadLetter(OrderUpdatedSubject, oc.handleOrderUpdate))
+	if err != nil {
+		drain(sub)
+		if oc.batchMode() {
+			oc.stopBatcher()
+		}
+		return fmt.Errorf("failed to subscribe to order updates: %w", err)
+	}
+
 	// Wait for context cancellation
 	<-ctx.Done()
 
-	// Unsubscribe when context is cancelled
-	if err := sub.Unsubscribe(); err != nil {
-		slog.Error("Failed to unsubscribe", "error", err)
+	// Drain when context is cancelled, so no handler is still running
+	// when the batcher is stopped
+	if err := drain(sub); err != nil {
+		slog.Error("Failed to drain subscription", "error", err)
+	}
+	if err := drain(updSub); err != nil {
+		slog.Error("Failed to drain update subscription", "error", err)
+	}
+
+	// Let the batcher flush what it has accumulated
+	if oc.batchMode() {
+		oc.stopBatcher()
 	}
 
 	slog.Info("Order consumer stopped")
 	return nil
 }
+
+// drain stops the subscription and waits until the handlers have processed
+// the messages already delivered to it
+func drain(sub *nats.Subscription) error {
+	closed := sub.StatusChanged(nats.SubscriptionClosed)
+	if err := sub.Drain(); err != nil {
+		return err
+	}
+
+	select {
+	case <-closed:
+		return nil
+	case <-time.After(drainTimeout):
+		return fmt.Errorf("subscription %s not drained within %s", sub.Subject, drainTimeout)
+	}
+}
+
+func (oc *OrderConsumer) batchMode() bool {
+	return oc.cfg.BatchSize > 1
+}
@@ -0,0 +1,83 @@
+package order
+
+import (
+	"context"
+	"encoding/json"
+	"os"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"wb-test/internal/models"
+)
+
+// fakeOrderService records the orders it is asked to store. fail decides the
+// error returned for an order, batchErr the error of every batch.
+type fakeOrderService struct {
+	mu       sync.Mutex
+	batches  [][]string
+	single   []string
+	updated  []string
+	fail     func(orderUID string) error
+	batchErr error
+}
+
+func (s *fakeOrderService) ProcessOrder(_ context.Context, order *models.Order) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.single = append(s.single, order.OrderUID)
+	if s.fail != nil {
+		return s.fail(order.OrderUID)
+	}
+	return nil
+}
+
+func (s *fakeOrderService) ProcessOrders(_ context.Context, orders []*models.Order) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	uids := make([]string, len(orders))
+	for i, order := range orders {
+		uids[i] = order.OrderUID
+	}
+	s.batches = append(s.batches, uids)
+	return s.batchErr
+}
+
+func (s *fakeOrderService) UpdateOrder(_ context.Context, order *models.Order) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.updated = append(s.updated, order.OrderUID)
+	if s.fail != nil {
+		return s.fail(order.OrderUID)
+	}
+	return nil
+}
+
+func (s *fakeOrderService) recordedBatches() [][]string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return append([][]string(nil), s.batches...)
+}
+
+func (s *fakeOrderService) recordedSingle() []string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return append([]string(nil), s.single...)
+}
+
+// orderPayload returns the sample order encoded under the given UID
+func orderPayload(t *testing.T, orderUID string) []byte {
+	t.Helper()
+
+	data, err := os.ReadFile("../../../sample_payload.json")
+	require.NoError(t, err)
+
+	var order models.Order
+	require.NoError(t, json.Unmarshal(data, &order))
+	order.OrderUID = orderUID
+
+	data, err = json.Marshal(order)
+	require.NoError(t, err)
+	return data
+}
@@ -0,0 +1,75 @@
+package order
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"log/slog"
+
+	"wb-test/internal/models"
+	"wb-test/pkg/broker"
+	"wb-test/pkg/metrics"
+	"wb-test/pkg/tracing"
+)
+
+// errNoDeadLetterQueue is returned by deadLetter when no queue is configured
+var errNoDeadLetterQueue = errors.New("no dead-letter queue configured")
+
+// withDeadLetter dead-letters messages the handler fails on. Core NATS does
+// not redeliver, so a failure is final.
+func (oc *OrderConsumer) withDeadLetter(subject string, handler func(context.Context, []byte) error) func(context.Context, []byte) error {
+	return func(ctx context.Context, data []byte) error {
+		metrics.MessagesReceived.WithLabelValues(subject).Inc()
+		ctx, span := broker.StartConsumerSpan(ctx, subject)
+		err := handler(ctx, data)
+		if err != nil {
+			oc.deadLetterOrDrop(subject, data, err)
+		}
+		tracing.End(span, err)
+		return err
+	}
+}
+
+// deadLetter publishes a payload that failed processing to the dead-letter
+// subject. An error means the payload is not stored anywhere, so the caller
+// must not drop the message.
+func (oc *OrderConsumer) deadLetter(subject string, data []byte, attempts int, cause error) error {
+	if oc.dlq == nil {
+		return errNoDeadLetterQueue
+	}
+
+	publish := oc.dlq.Publish
+	if errors.Is(cause, models.ErrOrderConflict) || errors.Is(cause, models.ErrOrderVersionMismatch) {
+		// Conflicting re-submissions need a human decision, keep them apart
+		publish = oc.dlq.PublishConflict
+	}
+
+	if err := publish(subject, data, attempts, cause); err != nil {
+		return err
+	}
+
+	metrics.MessagesFailed.WithLabelValues(subject, "dead_lettered").Inc()
+	slog.Warn("Order message dead-lettered", "error", cause, "subject", subject, "attempts", attempts)
+	return nil
+}
+
+// deadLetterOrDrop dead-letters a core NATS payload. Core NATS cannot
+// redeliver, so a payload the queue does not accept is lost.
+func (oc *OrderConsumer) deadLetterOrDrop(subject string, data []byte, cause error) {
+	if err := oc.deadLetter(subject, data, 1, cause); err != nil {
+		metrics.MessagesFailed.WithLabelValues(subject, "dropped").Inc()
+		slog.Error("Dropping failed order message", "error", err, "cause", cause, "subject", subject)
+	}
+}
+
+// deadLetterOrders re-encodes decoded orders that failed as a batch
+func (oc *OrderConsumer) deadLetterOrders(orders []*models.Order, cause error) {
+	for _, order := range orders {
+		data, err := json.Marshal(order)
+		if err != nil {
+			slog.Error("Failed to marshal order for dead-letter", "error", err, "order_uid", order.OrderUID)
+			continue
+		}
+		oc.deadLetterOrDrop(OrderSubject, data, cause)
+	}
+}
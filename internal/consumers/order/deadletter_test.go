@@ -0,0 +1,35 @@
+package order
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"wb-test/pkg/config"
+)
+
+func TestWithDeadLetterPublishesFailedMessage(t *testing.T) {
+	dlq := &fakeDeadLetterQueue{}
+	oc := NewOrderConsumer(nil, &fakeOrderService{}, dlq, config.ConsumerConfig{}, config.JetStreamConfig{})
+
+	handler := oc.withDeadLetter(OrderSubject, func(context.Context, []byte) error { return errors.New("boom") })
+	assert.Error(t, handler(context.Background(), []byte("{}")))
+
+	assert.Equal(t, []string{OrderSubject}, dlq.letters)
+	assert.Equal(t, []int{1}, dlq.attempts)
+}
+
+func TestDeadLetterFailsWithoutQueue(t *testing.T) {
+	oc := NewOrderConsumer(nil, &fakeOrderService{}, nil, config.ConsumerConfig{}, config.JetStreamConfig{})
+
+	assert.ErrorIs(t, oc.deadLetter(OrderSubject, []byte("{}"), 1, errors.New("boom")), errNoDeadLetterQueue)
+}
+
+func TestDeadLetterReturnsPublishError(t *testing.T) {
+	unavailable := errors.New("dead-letter stream unavailable")
+	oc := NewOrderConsumer(nil, &fakeOrderService{}, &fakeDeadLetterQueue{err: unavailable}, config.ConsumerConfig{}, config.JetStreamConfig{})
+
+	assert.ErrorIs(t, oc.deadLetter(OrderSubject, []byte("{}"), 1, errors.New("boom")), unavailable)
+}
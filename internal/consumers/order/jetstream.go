@@ -0,0 +1,238 @@
+package order
+
+import (
+	"context"
+	"log/slog"
+	"time"
+
+	"wb-test/internal/models"
+	"wb-test/pkg/broker"
+	"wb-test/pkg/metrics"
+	"wb-test/pkg/utils"
+
+	"github.com/nats-io/nats.go/jetstream"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// startJetStream consumes orders from a durable pull consumer. Messages are
+// acked only after they are stored, failures are redelivered after NakDelay
+// until MaxDeliver is reached.
+func (oc *OrderConsumer) startJetStream(ctx context.Context) error {
+	consumer, err := oc.jetStreamConsumer(ctx)
+	if err != nil {
+		return err
+	}
+
+	fetchSize := oc.jsCfg.FetchBatch
+	if oc.batchMode() {
+		fetchSize = oc.cfg.BatchSize
+	}
+
+	slog.Info("Starting JetStream order consumer",
+		"stream", oc.jsCfg.Stream,
+		"durable", oc.jsCfg.Durable,
+		"subjects", []string{OrderSubject, OrderUpdatedSubject},
+		"fetch_size", fetchSize,
+		"batch_mode", oc.batchMode(),
+	)
+
+	for ctx.Err() == nil {
+		batch, err := consumer.Fetch(fetchSize, jetstream.FetchMaxWait(oc.jsCfg.FetchWait))
+		if err != nil {
+			slog.Error("Failed to fetch orders", "error", err)
+			// Back off instead of spinning while the server is unavailable
+			select {
+			case <-ctx.Done():
+			case <-time.After(oc.jsCfg.FetchWait):
+			}
+			continue
+		}
+
+		var msgs []jetstream.Msg
+		for msg := range batch.Messages() {
+			metrics.MessagesReceived.WithLabelValues(msg.Subject()).Inc()
+			msgs = append(msgs, msg)
+		}
+		if err := batch.Error(); err != nil {
+			slog.Error("Order fetch finished with error", "error", err)
+		}
+
+		if oc.batchMode() {
+			oc.handleJetStreamBatch(msgs)
+			continue
+		}
+		for _, msg := range msgs {
+			oc.handleJetStreamMsg(msg)
+		}
+	}
+
+	slog.Info("Order consumer stopped")
+	return nil
+}
+
+func (oc *OrderConsumer) jetStreamConsumer(ctx context.Context) (jetstream.Consumer, error) {
+	if !oc.jsCfg.AutoProvision {
+		return oc.broker.PullConsumer(ctx, oc.jsCfg.Stream, oc.jsCfg.Durable)
+	}
+
+	if _, err := oc.broker.EnsureStream(ctx, broker.StreamConfig{
+		Name:     oc.jsCfg.Stream,
+		Subjects: oc.jsCfg.Subjects,
+		MaxAge:   oc.jsCfg.StreamMaxAge,
+	}); err != nil {
+		return nil, err
+	}
+
+	return oc.broker.EnsurePullConsumer(ctx, oc.jsCfg.Stream, broker.PullConsumerConfig{
+		Durable:        oc.jsCfg.Durable,
+		FilterSubjects: []string{OrderSubject, OrderUpdatedSubject},
+		MaxDeliver:     oc.jsCfg.MaxDeliver,
+		AckWait:        oc.jsCfg.AckWait,
+	})
+}
+
+func (oc *OrderConsumer) handleJetStreamMsg(msg jetstream.Msg) {
+	ctx, span := broker.StartConsumerSpan(broker.ExtractTrace(context.Background(), msg.Headers()), msg.Subject())
+	defer span.End()
+
+	order, err := decodeOrder(msg.Data())
+	if err != nil {
+		// Redelivering a malformed payload will never succeed
+		span.SetStatus(codes.Error, err.Error())
+		oc.term(msg, err)
+		return
+	}
+
+	oc.processJetStreamOrder(ctx, msg, order)
+}
+
+func (oc *OrderConsumer) processJetStreamOrder(ctx context.Context, msg jetstream.Msg, order *models.Order) {
+	if err := oc.process(ctx, msg.Subject(), order); err != nil {
+		trace.SpanFromContext(ctx).SetStatus(codes.Error, err.Error())
+		oc.handleFailure(msg, err)
+		return
+	}
+
+	if err := msg.Ack(); err != nil {
+		slog.Error("Failed to ack order", "error", err, "order_uid", order.OrderUID)
+	}
+}
+
+// handleJetStreamBatch stores all decodable messages of a fetch in one
+// transaction and acks or naks them together
+func (oc *OrderConsumer) handleJetStreamBatch(msgs []jetstream.Msg) {
+	orders := make([]*models.Order, 0, len(msgs))
+	decoded := make([]jetstream.Msg, 0, len(msgs))
+	links := make([]trace.Link, 0, len(msgs))
+	for _, msg := range msgs {
+		// Updates are never batched
+		if msg.Subject() == OrderUpdatedSubject {
+			oc.handleJetStreamMsg(msg)
+			continue
+		}
+
+		order, err := decodeOrder(msg.Data())
+		if err != nil {
+			oc.term(msg, err)
+			continue
+		}
+		orders = append(orders, order)
+		decoded = append(decoded, msg)
+		links = append(links, trace.LinkFromContext(broker.ExtractTrace(context.Background(), msg.Headers())))
+	}
+	if len(orders) == 0 {
+		return
+	}
+
+	// The batch gets its own trace linked to the traces of its messages
+	ctx, span := broker.StartConsumerSpan(context.Background(), OrderSubject,
+		trace.WithNewRoot(),
+		trace.WithLinks(links...),
+		trace.WithAttributes(attribute.Int("order.count", len(orders))),
+	)
+	defer span.End()
+
+	start := time.Now()
+	err := oc.service.ProcessOrders(ctx, orders)
+	metrics.ObserveProcessing("batch", start)
+	if err != nil {
+		slog.Error("Failed to process order batch", "error", err, "size", len(orders))
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+		if utils.IsPermanent(err) {
+			// Some order in the batch is bad, find it by storing them one by one
+			for i, msg := range decoded {
+				oc.processJetStreamOrder(ctx, msg, orders[i])
+			}
+			return
+		}
+		for _, msg := range decoded {
+			oc.retryOrDeadLetter(msg, err)
+		}
+		return
+	}
+
+	metrics.MessagesProcessed.WithLabelValues(OrderSubject).Add(float64(len(decoded)))
+	for _, msg := range decoded {
+		if err := msg.Ack(); err != nil {
+			slog.Error("Failed to ack order", "error", err)
+		}
+	}
+}
+
+// handleFailure dead-letters permanent failures right away and leaves
+// transient ones to redelivery
+func (oc *OrderConsumer) handleFailure(msg jetstream.Msg, cause error) {
+	if utils.IsPermanent(cause) {
+		oc.term(msg, cause)
+		return
+	}
+
+	oc.retryOrDeadLetter(msg, cause)
+}
+
+// retryOrDeadLetter naks the message for redelivery, or dead-letters it once
+// it has used up its deliveries
+func (oc *OrderConsumer) retryOrDeadLetter(msg jetstream.Msg, cause error) {
+	attempt := deliveryAttempt(msg)
+	if oc.jsCfg.MaxDeliver > 0 && attempt >= oc.jsCfg.MaxDeliver {
+		oc.term(msg, cause)
+		return
+	}
+
+	metrics.MessagesFailed.WithLabelValues(msg.Subject(), "redelivered").Inc()
+	slog.Warn("Order will be redelivered", "error", cause, "attempt", attempt, "delay", oc.jsCfg.NakDelay)
+	if err := msg.NakWithDelay(oc.jsCfg.NakDelay); err != nil {
+		slog.Error("Failed to nak order", "error", err)
+	}
+}
+
+// term dead-letters the message and stops its redelivery. Until the
+// dead-letter queue accepts the message it is nak'd instead, so it never
+// leaves the stream without a copy.
+func (oc *OrderConsumer) term(msg jetstream.Msg, cause error) {
+	if err := oc.deadLetter(msg.Subject(), msg.Data(), deliveryAttempt(msg), cause); err != nil {
+		metrics.MessagesFailed.WithLabelValues(msg.Subject(), "redelivered").Inc()
+		slog.Error("Failed to dead-letter order message, leaving it in the stream",
+			"error", err, "cause", cause, "subject", msg.Subject(), "delay", oc.jsCfg.NakDelay,
+		)
+		if err := msg.NakWithDelay(oc.jsCfg.NakDelay); err != nil {
+			slog.Error("Failed to nak order", "error", err)
+		}
+		return
+	}
+
+	if err := msg.Term(); err != nil {
+		slog.Error("Failed to terminate order message", "error", err)
+	}
+}
+
+func deliveryAttempt(msg jetstream.Msg) int {
+	meta, err := msg.Metadata()
+	if err != nil {
+		return 1
+	}
+	return int(meta.NumDelivered)
+}
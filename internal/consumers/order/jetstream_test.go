@@ -0,0 +1,278 @@
+package order
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/nats-io/nats.go"
+	"github.com/nats-io/nats.go/jetstream"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"wb-test/internal/models"
+	"wb-test/pkg/config"
+	"wb-test/pkg/utils"
+)
+
+// fakeMsg is a JetStream message that records how it was settled
+type fakeMsg struct {
+	subject   string
+	data      []byte
+	delivered uint64
+
+	acked    bool
+	naked    bool
+	nakDelay time.Duration
+	termed   bool
+}
+
+func (m *fakeMsg) Metadata() (*jetstream.MsgMetadata, error) {
+	return &jetstream.MsgMetadata{NumDelivered: m.delivered}, nil
+}
+func (m *fakeMsg) Data() []byte                       { return m.data }
+func (m *fakeMsg) Headers() nats.Header               { return nats.Header{} }
+func (m *fakeMsg) Subject() string                    { return m.subject }
+func (m *fakeMsg) Reply() string                      { return "" }
+func (m *fakeMsg) DoubleAck(context.Context) error    { return m.Ack() }
+func (m *fakeMsg) Nak() error                         { return m.NakWithDelay(0) }
+func (m *fakeMsg) InProgress() error                  { return nil }
+func (m *fakeMsg) TermWithReason(reason string) error { return m.Term() }
+func (m *fakeMsg) Ack() error                         { m.acked = true; return nil }
+func (m *fakeMsg) Term() error                        { m.termed = true; return nil }
+func (m *fakeMsg) NakWithDelay(delay time.Duration) error {
+	m.naked, m.nakDelay = true, delay
+	return nil
+}
+
+// settled reports the single way the message was settled
+func (m *fakeMsg) settled() string {
+	switch {
+	case m.acked && !m.naked && !m.termed:
+		return "ack"
+	case m.naked && !m.acked && !m.termed:
+		return "nak"
+	case m.termed && !m.acked && !m.naked:
+		return "term"
+	case !m.acked && !m.naked && !m.termed:
+		return "none"
+	}
+	return "multiple"
+}
+
+// fakeDeadLetterQueue records published dead letters, err fails every publish
+type fakeDeadLetterQueue struct {
+	mu        sync.Mutex
+	letters   []string
+	conflicts []string
+	attempts  []int
+	err       error
+}
+
+func (q *fakeDeadLetterQueue) Publish(originalSubject string, data []byte, attempts int, cause error) error {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	if q.err != nil {
+		return q.err
+	}
+	q.letters = append(q.letters, originalSubject)
+	q.attempts = append(q.attempts, attempts)
+	return nil
+}
+
+func (q *fakeDeadLetterQueue) PublishConflict(originalSubject string, data []byte, attempts int, cause error) error {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	if q.err != nil {
+		return q.err
+	}
+	q.conflicts = append(q.conflicts, originalSubject)
+	return nil
+}
+
+func newJetStreamConsumer(service *fakeOrderService, dlq *fakeDeadLetterQueue) *OrderConsumer {
+	return NewOrderConsumer(nil, service, dlq, config.ConsumerConfig{BatchSize: 1}, config.JetStreamConfig{
+		Enabled:    true,
+		MaxDeliver: 3,
+		NakDelay:   5 * time.Second,
+	})
+}
+
+func TestJetStreamAcksStoredOrder(t *testing.T) {
+	service := &fakeOrderService{}
+	oc := newJetStreamConsumer(service, &fakeDeadLetterQueue{})
+
+	msg := &fakeMsg{subject: OrderSubject, data: orderPayload(t, "a"), delivered: 1}
+	oc.handleJetStreamMsg(msg)
+
+	assert.Equal(t, "ack", msg.settled())
+	assert.Equal(t, []string{"a"}, service.recordedSingle())
+}
+
+func TestJetStreamRoutesUpdates(t *testing.T) {
+	service := &fakeOrderService{}
+	oc := newJetStreamConsumer(service, &fakeDeadLetterQueue{})
+
+	msg := &fakeMsg{subject: OrderUpdatedSubject, data: orderPayload(t, "a"), delivered: 1}
+	oc.handleJetStreamMsg(msg)
+
+	assert.Equal(t, "ack", msg.settled())
+	assert.Equal(t, []string{"a"}, service.updated)
+	assert.Empty(t, service.recordedSingle())
+}
+
+func TestJetStreamNaksTransientFailureWithDelay(t *testing.T) {
+	service := &fakeOrderService{fail: func(string) error { return errors.New("connection reset") }}
+	dlq := &fakeDeadLetterQueue{}
+	oc := newJetStreamConsumer(service, dlq)
+
+	msg := &fakeMsg{subject: OrderSubject, data: orderPayload(t, "a"), delivered: 2}
+	oc.handleJetStreamMsg(msg)
+
+	assert.Equal(t, "nak", msg.settled())
+	assert.Equal(t, 5*time.Second, msg.nakDelay)
+	assert.Empty(t, dlq.letters)
+}
+
+func TestJetStreamDeadLettersOnLastDelivery(t *testing.T) {
+	service := &fakeOrderService{fail: func(string) error { return errors.New("connection reset") }}
+	dlq := &fakeDeadLetterQueue{}
+	oc := newJetStreamConsumer(service, dlq)
+
+	msg := &fakeMsg{subject: OrderSubject, data: orderPayload(t, "a"), delivered: 3}
+	oc.handleJetStreamMsg(msg)
+
+	assert.Equal(t, "term", msg.settled())
+	assert.Equal(t, []string{OrderSubject}, dlq.letters)
+	assert.Equal(t, []int{3}, dlq.attempts)
+}
+
+func TestJetStreamTermsPermanentFailureRightAway(t *testing.T) {
+	service := &fakeOrderService{fail: func(string) error { return utils.Permanent(errors.New("constraint violation")) }}
+	dlq := &fakeDeadLetterQueue{}
+	oc := newJetStreamConsumer(service, dlq)
+
+	msg := &fakeMsg{subject: OrderSubject, data: orderPayload(t, "a"), delivered: 1}
+	oc.handleJetStreamMsg(msg)
+
+	assert.Equal(t, "term", msg.settled())
+	assert.Equal(t, []string{OrderSubject}, dlq.letters)
+}
+
+func TestJetStreamTermsMalformedPayload(t *testing.T) {
+	service := &fakeOrderService{}
+	dlq := &fakeDeadLetterQueue{}
+	oc := newJetStreamConsumer(service, dlq)
+
+	msg := &fakeMsg{subject: OrderSubject, data: []byte("{not json"), delivered: 1}
+	oc.handleJetStreamMsg(msg)
+
+	assert.Equal(t, "term", msg.settled())
+	assert.Equal(t, []string{OrderSubject}, dlq.letters)
+	assert.Empty(t, service.recordedSingle())
+}
+
+func TestJetStreamBatchAcksAllStoredOrders(t *testing.T) {
+	service := &fakeOrderService{}
+	oc := newJetStreamConsumer(service, &fakeDeadLetterQueue{})
+
+	msgs := []*fakeMsg{
+		{subject: OrderSubject, data: orderPayload(t, "a"), delivered: 1},
+		{subject: OrderSubject, data: orderPayload(t, "b"), delivered: 1},
+		{subject: OrderUpdatedSubject, data: orderPayload(t, "c"), delivered: 1},
+	}
+	oc.handleJetStreamBatch(jetStreamMsgs(msgs))
+
+	for _, msg := range msgs {
+		assert.Equal(t, "ack", msg.settled())
+	}
+	assert.Equal(t, [][]string{{"a", "b"}}, service.recordedBatches())
+	assert.Equal(t, []string{"c"}, service.updated)
+}
+
+func TestJetStreamBatchNaksAllOnTransientFailure(t *testing.T) {
+	service := &fakeOrderService{batchErr: errors.New("connection reset")}
+	oc := newJetStreamConsumer(service, &fakeDeadLetterQueue{})
+
+	msgs := []*fakeMsg{
+		{subject: OrderSubject, data: orderPayload(t, "a"), delivered: 1},
+		{subject: OrderSubject, data: orderPayload(t, "b"), delivered: 1},
+	}
+	oc.handleJetStreamBatch(jetStreamMsgs(msgs))
+
+	for _, msg := range msgs {
+		assert.Equal(t, "nak", msg.settled())
+	}
+	assert.Empty(t, service.recordedSingle())
+}
+
+func TestJetStreamBatchIsolatesPermanentFailure(t *testing.T) {
+	bad := utils.Permanent(errors.New("constraint violation"))
+	service := &fakeOrderService{
+		batchErr: bad,
+		fail: func(orderUID string) error {
+			if orderUID == "b" {
+				return bad
+			}
+			return nil
+		},
+	}
+	dlq := &fakeDeadLetterQueue{}
+	oc := newJetStreamConsumer(service, dlq)
+
+	msgs := []*fakeMsg{
+		{subject: OrderSubject, data: orderPayload(t, "a"), delivered: 1},
+		{subject: OrderSubject, data: orderPayload(t, "b"), delivered: 1},
+		{subject: OrderSubject, data: orderPayload(t, "c"), delivered: 1},
+	}
+	oc.handleJetStreamBatch(jetStreamMsgs(msgs))
+
+	require.Equal(t, []string{"a", "b", "c"}, service.recordedSingle())
+	assert.Equal(t, "ack", msgs[0].settled())
+	assert.Equal(t, "term", msgs[1].settled())
+	assert.Equal(t, "ack", msgs[2].settled())
+	assert.Len(t, dlq.letters, 1)
+}
+
+func jetStreamMsgs(msgs []*fakeMsg) []jetstream.Msg {
+	out := make([]jetstream.Msg, len(msgs))
+	for i, msg := range msgs {
+		out[i] = msg
+	}
+	return out
+}
+
+func TestJetStreamKeepsMessageWhenDeadLetterFails(t *testing.T) {
+	service := &fakeOrderService{fail: func(string) error { return errors.New("connection reset") }}
+	oc := newJetStreamConsumer(service, &fakeDeadLetterQueue{err: errors.New("dead-letter stream unavailable")})
+
+	msg := &fakeMsg{subject: OrderSubject, data: orderPayload(t, "a"), delivered: 3}
+	oc.handleJetStreamMsg(msg)
+
+	assert.Equal(t, "nak", msg.settled())
+	assert.Equal(t, 5*time.Second, msg.nakDelay)
+}
+
+func TestJetStreamKeepsMalformedPayloadWithoutDeadLetterQueue(t *testing.T) {
+	oc := NewOrderConsumer(nil, &fakeOrderService{}, nil, config.ConsumerConfig{}, config.JetStreamConfig{Enabled: true, MaxDeliver: 3})
+
+	msg := &fakeMsg{subject: OrderSubject, data: []byte("{not json"), delivered: 1}
+	oc.handleJetStreamMsg(msg)
+
+	assert.Equal(t, "nak", msg.settled())
+}
+
+func TestJetStreamSendsConflictsToConflictSubject(t *testing.T) {
+	service := &fakeOrderService{fail: func(string) error { return utils.Permanent(models.ErrOrderConflict) }}
+	dlq := &fakeDeadLetterQueue{}
+	oc := newJetStreamConsumer(service, dlq)
+
+	msg := &fakeMsg{subject: OrderSubject, data: orderPayload(t, "a"), delivered: 1}
+	oc.handleJetStreamMsg(msg)
+
+	assert.Equal(t, "term", msg.settled())
+	assert.Empty(t, dlq.letters)
+	assert.Equal(t, []string{OrderSubject}, dlq.conflicts)
+}
@@ -1,20 +1,28 @@
 package order
 
 import (
+	"context"
 	"encoding/json"
 	"fmt"
 	"log/slog"
+	"time"
 
 	"wb-test/internal/models"
+	"wb-test/internal/validation"
+	"wb-test/pkg/metrics"
+	"wb-test/pkg/utils"
 )
 
-func (oc *OrderConsumer) handleOrder(data []byte) error {
-	var order models.Order
-	if err := json.Unmarshal(data, &order); err != nil {
-		slog.Error("Failed to unmarshal order", "error", err)
-		return fmt.Errorf("failed to unmarshal order: %w", err)
+func (oc *OrderConsumer) handleOrder(ctx context.Context, data []byte) error {
+	order, err := decodeOrder(data)
+	if err != nil {
+		return err
 	}
 
+	return oc.processOrder(ctx, order)
+}
+
+func (oc *OrderConsumer) processOrder(ctx context.Context, order *models.Order) error {
 	slog.Info("Processing order",
 		"order_uid", order.OrderUID,
 		"track_number", order.TrackNumber,
@@ -22,11 +30,63 @@ func (oc *OrderConsumer) handleOrder(data []byte) error {
 	)
 
 	// Process the order (save to DB, cache, etc.)
-	if err := oc.service.ProcessOrder(&order); err != nil {
+	start := time.Now()
+	err := oc.service.ProcessOrder(ctx, order)
+	metrics.ObserveProcessing("create", start)
+	if err != nil {
 		slog.Error("Failed to process order", "error", err)
 		return fmt.Errorf("failed to process order %s: %w", order.OrderUID, err)
 	}
 
+	metrics.MessagesProcessed.WithLabelValues(OrderSubject).Inc()
 	slog.Info("Order processed successfully", "order_uid", order.OrderUID)
 	return nil
 }
+
+func (oc *OrderConsumer) handleOrderUpdate(ctx context.Context, data []byte) error {
+	order, err := decodeOrder(data)
+	if err != nil {
+		return err
+	}
+
+	return oc.updateOrder(ctx, order)
+}
+
+func (oc *OrderConsumer) updateOrder(ctx context.Context, order *models.Order) error {
+	slog.Info("Updating order", "order_uid", order.OrderUID, "version", order.Version)
+
+	start := time.Now()
+	err := oc.service.UpdateOrder(ctx, order)
+	metrics.ObserveProcessing("update", start)
+	if err != nil {
+		slog.Error("Failed to update order", "error", err)
+		return fmt.Errorf("failed to update order %s: %w", order.OrderUID, err)
+	}
+
+	metrics.MessagesProcessed.WithLabelValues(OrderUpdatedSubject).Inc()
+	return nil
+}
+
+// process stores a decoded order according to the subject it arrived on
+func (oc *OrderConsumer) process(ctx context.Context, subject string, order *models.Order) error {
+	if subject == OrderUpdatedSubject {
+		return oc.updateOrder(ctx, order)
+	}
+	return oc.processOrder(ctx, order)
+}
+
+func decodeOrder(data []byte) (*models.Order, error) {
+	var order models.Order
+	if err := json.Unmarshal(data, &order); err != nil {
+		slog.Error("Failed to unmarshal order", "error", err)
+		return nil, utils.Permanent(fmt.Errorf("failed to unmarshal order: %w", err))
+	}
+
+	// Reject orders that break business rules before they reach the database
+	if err := validation.ValidateOrder(&order); err != nil {
+		slog.Error("Invalid order", "error", err, "order_uid", order.OrderUID)
+		return nil, utils.Permanent(err)
+	}
+
+	return &order, nil
+}
@@ -0,0 +1,126 @@
+package apikey
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strconv"
+	"time"
+
+	"wb-test/internal/models"
+	httputils "wb-test/pkg/utils/http-utils"
+
+	"github.com/gorilla/mux"
+)
+
+type APIKeyService interface {
+	CreateAPIKey(name string, scopes []string, expiresAt *time.Time) (*models.CreatedAPIKey, error)
+	ListAPIKeys() ([]*models.APIKey, error)
+	RevokeAPIKey(id int) error
+}
+
+type Handler struct {
+	service APIKeyService
+}
+
+func NewHandler(service APIKeyService) *Handler {
+	return &Handler{service: service}
+}
+
+// Create godoc
+//
+//	@Summary		Create API key
+//	@Description	Creates an API key for a service caller. The key is only returned in this response, store it right away.
+//	@Tags			API keys
+//	@Accept			json
+//	@Produce		json
+//	@Security		BearerAuth
+//	@Param			request	body		models.CreateAPIKeyRequest	true	"API key"
+//	@Success		201		{object}	models.CreatedAPIKey		"created key"
+//	@Failure		400		{object}	httputils.ErrorResponse		"invalid request body"
+//	@Failure		401		{object}	httputils.ErrorResponse		"missing, expired or invalid token"
+//	@Failure		403		{object}	httputils.ErrorResponse		"missing role or scope"
+//	@Failure		500		{object}	httputils.ErrorResponse		"internal server error"
+//	@Router			/api-keys [post]
+func (h *Handler) Create(w http.ResponseWriter, r *http.Request) {
+	var req models.CreateAPIKeyRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		httputils.WriteResponse(w, http.StatusBadRequest, "invalid request body", err, nil)
+		return
+	}
+	if req.Name == "" {
+		httputils.WriteResponse(w, http.StatusBadRequest, "invalid request body", errors.New("name is required"), nil)
+		return
+	}
+	if req.ExpiresAt != nil && !req.ExpiresAt.After(time.Now()) {
+		httputils.WriteResponse(w, http.StatusBadRequest, "invalid request body", errors.New("expires_at must be in the future"), nil)
+		return
+	}
+
+	key, err := h.service.CreateAPIKey(req.Name, req.Scopes, req.ExpiresAt)
+	if err != nil {
+		httputils.WriteResponse(w, http.StatusInternalServerError, "internal server error", err, nil)
+		return
+	}
+
+	w.Header().Set("Cache-Control", "no-store")
+	httputils.WriteResponse(w, http.StatusCreated, "created", nil, key)
+}
+
+// List godoc
+//
+//	@Summary		List API keys
+//	@Description	Lists all API keys, revoked and expired ones included. Keys themselves are never returned, only their prefix.
+//	@Tags			API keys
+//	@Accept			json
+//	@Produce		json
+//	@Security		BearerAuth
+//	@Success		200	{array}		models.APIKey			"API keys"
+//	@Failure		401	{object}	httputils.ErrorResponse	"missing, expired or invalid token"
+//	@Failure		403	{object}	httputils.ErrorResponse	"missing role or scope"
+//	@Failure		500	{object}	httputils.ErrorResponse	"internal server error"
+//	@Router			/api-keys [get]
+func (h *Handler) List(w http.ResponseWriter, r *http.Request) {
+	keys, err := h.service.ListAPIKeys()
+	if err != nil {
+		httputils.WriteResponse(w, http.StatusInternalServerError, "internal server error", err, nil)
+		return
+	}
+
+	httputils.WriteResponse(w, http.StatusOK, "ok", nil, keys)
+}
+
+// Revoke godoc
+//
+//	@Summary		Revoke API key
+//	@Description	Revokes an API key, requests with it are refused from now on.
+//	@Tags			API keys
+//	@Accept			json
+//	@Produce		json
+//	@Security		BearerAuth
+//	@Param			id	path		int						true	"API key ID"
+//	@Success		200	{object}	httputils.Status		"revoked"
+//	@Failure		400	{object}	httputils.ErrorResponse	"invalid id"
+//	@Failure		401	{object}	httputils.ErrorResponse	"missing, expired or invalid token"
+//	@Failure		403	{object}	httputils.ErrorResponse	"missing role or scope"
+//	@Failure		404	{object}	httputils.ErrorResponse	"api key not found"
+//	@Failure		500	{object}	httputils.ErrorResponse	"internal server error"
+//	@Router			/api-keys/{id} [delete]
+func (h *Handler) Revoke(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.Atoi(mux.Vars(r)["id"])
+	if err != nil {
+		httputils.WriteResponse(w, http.StatusBadRequest, "invalid id", err, nil)
+		return
+	}
+
+	if err := h.service.RevokeAPIKey(id); err != nil {
+		if errors.Is(err, models.ErrAPIKeyNotFound) {
+			httputils.WriteResponse(w, http.StatusNotFound, "api key not found", err, nil)
+			return
+		}
+		httputils.WriteResponse(w, http.StatusInternalServerError, "internal server error", err, nil)
+		return
+	}
+
+	httputils.WriteResponse(w, http.StatusOK, "revoked", nil, nil)
+}
@@ -0,0 +1,171 @@
+package auth
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+
+	"wb-test/internal/models"
+	httputils "wb-test/pkg/utils/http-utils"
+	"wb-test/pkg/utils/jwt"
+)
+
+// KeyPublisher exposes the public keys tokens are verified with
+type KeyPublisher interface {
+	JWKS() jwt.JWKSet
+}
+
+type AuthService interface {
+	Login(username, password string) (*models.TokenPair, error)
+	Refresh(refreshToken string) (*models.TokenPair, error)
+	Logout(claims *jwt.Claims, refreshToken string) error
+}
+
+type Handler struct {
+	keys    KeyPublisher
+	service AuthService
+}
+
+func NewHandler(keys KeyPublisher, service AuthService) *Handler {
+	return &Handler{keys: keys, service: service}
+}
+
+// JWKS godoc
+//
+//	@Summary		Public signing keys
+//	@Description	Publishes the public keys of asymmetric token signing keys, HMAC keys are never listed.
+//	@Tags			Auth
+//	@Accept			json
+//	@Produce		json
+//	@Success		200	{object}	jwt.JWKSet	"key set"
+//	@Router			/.well-known/jwks.json [get]
+func (h *Handler) JWKS(w http.ResponseWriter, r *http.Request) {
+	// Let verifiers cache the set, rotated keys show up within the hour
+	w.Header().Set("Cache-Control", "public, max-age=3600")
+	httputils.WriteResponse(w, http.StatusOK, "ok", nil, h.keys.JWKS())
+}
+
+// Login godoc
+//
+//	@Summary		Log in
+//	@Description	Exchanges a username and password for an access and refresh token. Repeated failures lock the user for a while.
+//	@Tags			Auth
+//	@Accept			json
+//	@Produce		json
+//	@Param			request	body		models.LoginRequest		true	"Credentials"
+//	@Success		200		{object}	models.TokenPair		"token pair"
+//	@Failure		400		{object}	httputils.ErrorResponse	"invalid request body"
+//	@Failure		401		{object}	httputils.ErrorResponse	"invalid username or password"
+//	@Failure		423		{object}	httputils.ErrorResponse	"user locked"
+//	@Failure		500		{object}	httputils.ErrorResponse	"internal server error"
+//	@Router			/auth/login [post]
+func (h *Handler) Login(w http.ResponseWriter, r *http.Request) {
+	var req models.LoginRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		httputils.WriteResponse(w, http.StatusBadRequest, "invalid request body", err, nil)
+		return
+	}
+	if req.Username == "" || req.Password == "" {
+		httputils.WriteResponse(w, http.StatusBadRequest, "invalid request body", errors.New("username and password are required"), nil)
+		return
+	}
+
+	pair, err := h.service.Login(req.Username, req.Password)
+	if err != nil {
+		switch {
+		case errors.Is(err, models.ErrInvalidCredentials):
+			httputils.WriteResponse(w, http.StatusUnauthorized, "invalid username or password", err, nil)
+		case errors.Is(err, models.ErrUserLocked):
+			httputils.WriteResponse(w, http.StatusLocked, "user locked", err, nil)
+		default:
+			httputils.WriteResponse(w, http.StatusInternalServerError, "internal server error", err, nil)
+		}
+		return
+	}
+
+	w.Header().Set("Cache-Control", "no-store")
+	httputils.WriteResponse(w, http.StatusOK, "ok", nil, pair)
+}
+
+// Refresh godoc
+//
+//	@Summary		Refresh tokens
+//	@Description	Exchanges a refresh token for a new access and refresh token. Each refresh token works once, reusing one revokes all tokens rotated from the same login.
+//	@Tags			Auth
+//	@Accept			json
+//	@Produce		json
+//	@Param			request	body		models.RefreshRequest	true	"Refresh token"
+//	@Success		200		{object}	models.TokenPair		"new token pair"
+//	@Failure		400		{object}	httputils.ErrorResponse	"invalid request body"
+//	@Failure		401		{object}	httputils.ErrorResponse	"invalid or reused refresh token"
+//	@Failure		423		{object}	httputils.ErrorResponse	"user locked"
+//	@Failure		500		{object}	httputils.ErrorResponse	"internal server error"
+//	@Router			/auth/refresh [post]
+func (h *Handler) Refresh(w http.ResponseWriter, r *http.Request) {
+	var req models.RefreshRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		httputils.WriteResponse(w, http.StatusBadRequest, "invalid request body", err, nil)
+		return
+	}
+	if req.RefreshToken == "" {
+		httputils.WriteResponse(w, http.StatusBadRequest, "invalid request body", errors.New("refresh_token is required"), nil)
+		return
+	}
+
+	pair, err := h.service.Refresh(req.RefreshToken)
+	if err != nil {
+		switch {
+		case errors.Is(err, models.ErrInvalidRefreshToken):
+			httputils.WriteResponse(w, http.StatusUnauthorized, "invalid refresh token", err, nil)
+		case errors.Is(err, models.ErrRefreshTokenReused):
+			httputils.WriteResponse(w, http.StatusUnauthorized, "refresh token reused", err, nil)
+		case errors.Is(err, models.ErrUserLocked):
+			httputils.WriteResponse(w, http.StatusLocked, "user locked", err, nil)
+		default:
+			httputils.WriteResponse(w, http.StatusInternalServerError, "internal server error", err, nil)
+		}
+		return
+	}
+
+	// Tokens must not end up in shared caches
+	w.Header().Set("Cache-Control", "no-store")
+	httputils.WriteResponse(w, http.StatusOK, "ok", nil, pair)
+}
+
+// Logout godoc
+//
+//	@Summary		Log out
+//	@Description	Revokes the access token of the request and, when given, every refresh token of the same login.
+//	@Tags			Auth
+//	@Accept			json
+//	@Produce		json
+//	@Security		BearerAuth
+//	@Param			request	body		models.RefreshRequest	false	"Refresh token"
+//	@Success		200		{object}	httputils.Status		"logged out"
+//	@Failure		400		{object}	httputils.ErrorResponse	"invalid request body"
+//	@Failure		401		{object}	httputils.ErrorResponse	"missing, expired or invalid token"
+//	@Failure		500		{object}	httputils.ErrorResponse	"internal server error"
+//	@Router			/auth/logout [post]
+func (h *Handler) Logout(w http.ResponseWriter, r *http.Request) {
+	claims, ok := jwt.GetUserFromContext(r.Context())
+	if !ok {
+		httputils.WriteResponse(w, http.StatusUnauthorized, "unauthorized", jwt.ErrMissingToken, nil)
+		return
+	}
+
+	// The body is optional, without it only the access token is revoked
+	var req models.RefreshRequest
+	if r.ContentLength != 0 {
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			httputils.WriteResponse(w, http.StatusBadRequest, "invalid request body", err, nil)
+			return
+		}
+	}
+
+	if err := h.service.Logout(claims, req.RefreshToken); err != nil {
+		httputils.WriteResponse(w, http.StatusInternalServerError, "internal server error", err, nil)
+		return
+	}
+
+	httputils.WriteResponse(w, http.StatusOK, "logged out", nil, nil)
+}
@@ -0,0 +1,106 @@
+package deadletter
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+
+	"wb-test/pkg/broker"
+	httputils "wb-test/pkg/utils/http-utils"
+
+	"github.com/gorilla/mux"
+)
+
+const (
+	defaultLimit = 50
+	maxLimit     = 1000
+)
+
+type Queue interface {
+	List(ctx context.Context, limit int) ([]broker.DeadLetter, error)
+	Replay(ctx context.Context, seq uint64, fallbackSubject string) error
+}
+
+type Handler struct {
+	queue         Queue
+	replaySubject string
+}
+
+// NewHandler creates a dead-letter handler, letters without an original
+// subject are replayed to replaySubject
+func NewHandler(queue Queue, replaySubject string) *Handler {
+	return &Handler{
+		queue:         queue,
+		replaySubject: replaySubject,
+	}
+}
+
+// List godoc
+//
+//	@Summary	List dead-lettered order messages
+//	@Tags		Dead letters
+//	@Accept		json
+//	@Produce	json
+//	@Security	BearerAuth
+//	@Param		limit	query		int						false	"Max number of letters (default 50, max 1000)"
+//	@Success	200		{array}		broker.DeadLetter		"dead letters"
+//	@Failure	400		{object}	httputils.ErrorResponse	"invalid limit"
+//	@Failure	401		{object}	httputils.ErrorResponse	"missing, expired or invalid token"
+//	@Failure	403		{object}	httputils.ErrorResponse	"missing role or scope"
+//	@Failure	500		{object}	httputils.ErrorResponse	"internal server error"
+//	@Router		/dead-letters [get]
+func (h *Handler) List(w http.ResponseWriter, r *http.Request) {
+	limit := defaultLimit
+	if v := r.URL.Query().Get("limit"); v != "" {
+		n, err := strconv.Atoi(v)
+		if err != nil || n <= 0 || n > maxLimit {
+			httputils.WriteResponse(w, http.StatusBadRequest, "invalid limit", fmt.Errorf("limit must be between 1 and %d", maxLimit), nil)
+			return
+		}
+		limit = n
+	}
+
+	letters, err := h.queue.List(r.Context(), limit)
+	if err != nil {
+		httputils.WriteResponse(w, http.StatusInternalServerError, "internal server error", err, nil)
+		return
+	}
+
+	httputils.WriteResponse(w, http.StatusOK, "ok", nil, letters)
+}
+
+// Replay godoc
+//
+//	@Summary	Replay a dead-lettered order message to its original subject
+//	@Tags		Dead letters
+//	@Accept		json
+//	@Produce	json
+//	@Security	BearerAuth
+//	@Param		sequence	path		int						true	"Dead letter sequence"
+//	@Success	200			{object}	httputils.Status		"replayed"
+//	@Failure	400			{object}	httputils.ErrorResponse	"invalid sequence"
+//	@Failure	401			{object}	httputils.ErrorResponse	"missing, expired or invalid token"
+//	@Failure	403			{object}	httputils.ErrorResponse	"missing role or scope"
+//	@Failure	404			{object}	httputils.ErrorResponse	"dead letter not found"
+//	@Failure	500			{object}	httputils.ErrorResponse	"internal server error"
+//	@Router		/dead-letters/{sequence}/replay [post]
+func (h *Handler) Replay(w http.ResponseWriter, r *http.Request) {
+	seq, err := strconv.ParseUint(mux.Vars(r)["sequence"], 10, 64)
+	if err != nil {
+		httputils.WriteResponse(w, http.StatusBadRequest, "invalid sequence", err, nil)
+		return
+	}
+
+	if err := h.queue.Replay(r.Context(), seq, h.replaySubject); err != nil {
+		if errors.Is(err, broker.ErrDeadLetterNotFound) {
+			httputils.WriteResponse(w, http.StatusNotFound, "dead letter not found", err, nil)
+			return
+		}
+		httputils.WriteResponse(w, http.StatusInternalServerError, "internal server error", err, nil)
+		return
+	}
+
+	httputils.WriteResponse(w, http.StatusOK, "replayed", nil, nil)
+}
@@ -1,13 +1,48 @@
 package handler
 
-import "wb-test/internal/handlers/health"
+import (
+	orderconsumer "wb-test/internal/consumers/order"
+	"wb-test/internal/handlers/apikey"
+	"wb-test/internal/handlers/auth"
+	"wb-test/internal/handlers/deadletter"
+	"wb-test/internal/handlers/health"
+	"wb-test/internal/handlers/middleware"
+	"wb-test/internal/handlers/order"
+	"wb-test/internal/handlers/ui"
+)
+
+// TokenKeys verifies bearer tokens and publishes the public keys
+type TokenKeys interface {
+	middleware.TokenParser
+	auth.KeyPublisher
+}
+
+// APIKeys manages API keys and authenticates their callers
+type APIKeys interface {
+	apikey.APIKeyService
+	middleware.APIKeyAuthenticator
+}
 
 type Handler struct {
-	health *health.Handler
+	health     *health.Handler
+	auth       *auth.Handler
+	order      *order.Handler
+	deadletter *deadletter.Handler
+	apikey     *apikey.Handler
+	ui         *ui.Handler
+	tokens     middleware.TokenParser
+	apiKeys    middleware.APIKeyAuthenticator
 }
 
-func NewHandler() *Handler {
+func NewHandler(orderService order.OrderService, deadLetters deadletter.Queue, tokens TokenKeys, authService auth.AuthService, apiKeys APIKeys) *Handler {
 	return &Handler{
-		health: health.NewHandler(),
+		health:     health.NewHandler(),
+		auth:       auth.NewHandler(tokens, authService),
+		order:      order.NewHandler(orderService),
+		deadletter: deadletter.NewHandler(deadLetters, orderconsumer.OrderSubject),
+		apikey:     apikey.NewHandler(apiKeys),
+		ui:         ui.NewHandler(),
+		tokens:     tokens,
+		apiKeys:    apiKeys,
 	}
 }
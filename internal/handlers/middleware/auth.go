@@ -0,0 +1,133 @@
+package middleware
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+
+	"wb-test/internal/models"
+	httputils "wb-test/pkg/utils/http-utils"
+	"wb-test/pkg/utils/jwt"
+
+	"github.com/gorilla/mux"
+)
+
+// TokenParser validates a bearer token and returns its claims
+type TokenParser interface {
+	ParseJWT(token string) (*jwt.Claims, error)
+}
+
+// APIKeyHeader carries the API key of service-to-service callers
+const APIKeyHeader = "X-API-Key"
+
+// APIKeyAuthenticator checks an API key and returns its record
+type APIKeyAuthenticator interface {
+	AuthenticateAPIKey(key string) (*models.APIKey, error)
+}
+
+// Auth requires a valid bearer token or API key on every route not marked
+// public and checks the roles and scopes required by the route
+type Auth struct {
+	tokens  TokenParser
+	apiKeys APIKeyAuthenticator
+	public  map[*mux.Route]struct{}
+	rules   map[*mux.Route]*rule
+}
+
+// NewAuth creates the auth middleware, API keys are ignored when apiKeys is nil
+func NewAuth(tokens TokenParser, apiKeys APIKeyAuthenticator) *Auth {
+	return &Auth{
+		tokens:  tokens,
+		apiKeys: apiKeys,
+		public:  make(map[*mux.Route]struct{}),
+		rules:   make(map[*mux.Route]*rule),
+	}
+}
+
+// Public marks a route as reachable without a token, routes are marked
+// while building the router, before it serves requests
+func (a *Auth) Public(route *mux.Route) *mux.Route {
+	a.public[route] = struct{}{}
+	return route
+}
+
+func (a *Auth) isPublic(r *http.Request) bool {
+	route := mux.CurrentRoute(r)
+	if route == nil {
+		return false
+	}
+	_, ok := a.public[route]
+	return ok
+}
+
+// Middleware validates the bearer token and stores its claims in the request context
+func (a *Auth) Middleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if a.isPublic(r) {
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		var claims *jwt.Claims
+		if key := r.Header.Get(APIKeyHeader); key != "" && a.apiKeys != nil {
+			apiKey, err := a.apiKeys.AuthenticateAPIKey(key)
+			if err != nil {
+				if errors.Is(err, models.ErrInvalidAPIKey) {
+					httputils.WriteResponse(w, http.StatusUnauthorized, "invalid api key", err, nil)
+					return
+				}
+				httputils.WriteResponse(w, http.StatusInternalServerError, "internal server error", err, nil)
+				return
+			}
+			claims = apiKeyClaims(apiKey)
+		} else {
+			token, err := jwt.ExtractTokenFromHeader(r)
+			if err != nil {
+				unauthorized(w, err)
+				return
+			}
+
+			claims, err = a.tokens.ParseJWT(token)
+			if err != nil {
+				unauthorized(w, err)
+				return
+			}
+		}
+
+		if err := a.authorize(r, claims); err != nil {
+			httputils.WriteResponse(w, http.StatusForbidden, "forbidden", err, nil)
+			return
+		}
+
+		next.ServeHTTP(w, r.WithContext(jwt.ContextWithUser(r.Context(), claims)))
+	})
+}
+
+// apiKeyClaims lets handlers and scope checks treat API key callers like
+// token holders. API keys carry scopes only, never roles.
+func apiKeyClaims(key *models.APIKey) *jwt.Claims {
+	claims := &jwt.Claims{
+		Username: key.Name,
+		Scopes:   key.Scopes,
+	}
+	claims.Subject = fmt.Sprintf("api-key:%d", key.ID)
+	return claims
+}
+
+// unauthorized writes a 401 telling a missing token apart from an expired or invalid one
+func unauthorized(w http.ResponseWriter, err error) {
+	switch {
+	case errors.Is(err, jwt.ErrMissingToken):
+		w.Header().Set("WWW-Authenticate", `Bearer`)
+		httputils.WriteResponse(w, http.StatusUnauthorized, "missing token", err, nil)
+	case errors.Is(err, jwt.ErrRevokedToken):
+		w.Header().Set("WWW-Authenticate", `Bearer error="invalid_token", error_description="token revoked"`)
+		httputils.WriteResponse(w, http.StatusUnauthorized, "token revoked", err, nil)
+	case errors.Is(err, jwt.ErrExpiredToken):
+		w.Header().Set("WWW-Authenticate", `Bearer error="invalid_token", error_description="token expired"`)
+		httputils.WriteResponse(w, http.StatusUnauthorized, "token expired", err, nil)
+	default:
+		w.Header().Set("WWW-Authenticate", `Bearer error="invalid_token"`)
+		httputils.WriteResponse(w, http.StatusUnauthorized, "invalid token", jwt.ErrInvalidToken, nil)
+	}
+}
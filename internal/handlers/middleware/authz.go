@@ -0,0 +1,72 @@
+package middleware
+
+import (
+	"fmt"
+	"net/http"
+
+	"wb-test/pkg/utils/jwt"
+
+	"github.com/gorilla/mux"
+)
+
+// Scopes and roles checked on routes
+const (
+	ScopeOrdersRead  = "orders:read"
+	ScopeOrdersWrite = "orders:write"
+
+	// RoleAdmin passes every role and scope check
+	RoleAdmin = "admin"
+)
+
+// rule lists what a token needs to reach a route
+type rule struct {
+	scopes []string
+	roles  []string
+}
+
+func (a *Auth) rule(route *mux.Route) *rule {
+	r, ok := a.rules[route]
+	if !ok {
+		r = &rule{}
+		a.rules[route] = r
+	}
+	return r
+}
+
+// RequireScope makes a route reachable only with all of the scopes
+func (a *Auth) RequireScope(route *mux.Route, scopes ...string) *mux.Route {
+	r := a.rule(route)
+	r.scopes = append(r.scopes, scopes...)
+	return route
+}
+
+// RequireRole makes a route reachable only with all of the roles
+func (a *Auth) RequireRole(route *mux.Route, roles ...string) *mux.Route {
+	r := a.rule(route)
+	r.roles = append(r.roles, roles...)
+	return route
+}
+
+// authorize checks the claims against the rule of the current route
+func (a *Auth) authorize(r *http.Request, claims *jwt.Claims) error {
+	route := mux.CurrentRoute(r)
+	if route == nil {
+		return nil
+	}
+	rule, ok := a.rules[route]
+	if !ok || claims.HasRole(RoleAdmin) {
+		return nil
+	}
+
+	for _, role := range rule.roles {
+		if !claims.HasRole(role) {
+			return fmt.Errorf("missing role %s", role)
+		}
+	}
+	for _, scope := range rule.scopes {
+		if !claims.HasScope(scope) {
+			return fmt.Errorf("missing scope %s", scope)
+		}
+	}
+	return nil
+}
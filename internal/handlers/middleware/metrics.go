@@ -0,0 +1,62 @@
+package middleware
+
+import (
+	"context"
+	"net/http"
+	"strconv"
+	"time"
+
+	"wb-test/pkg/metrics"
+
+	"github.com/gorilla/mux"
+)
+
+// statusRecorder remembers the status code written by the handler
+type statusRecorder struct {
+	http.ResponseWriter
+	status int
+}
+
+func (r *statusRecorder) WriteHeader(status int) {
+	r.status = status
+	r.ResponseWriter.WriteHeader(status)
+}
+
+// routeKey carries a pointer to the route label of the request being measured
+type routeKey struct{}
+
+// Metrics records the duration of every request by route template, so
+// /orders/{order_uid} is one series no matter how many orders are read. It
+// wraps the router rather than being added with Use, so requests no route
+// matches (404 and 405) are recorded too, under the "unmatched" route. It
+// must be called before any other middleware is added to the router, so
+// requests those reject still get their route.
+func Metrics(router *mux.Router) http.Handler {
+	router.Use(recordRoute)
+
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		start := time.Now()
+		rec := &statusRecorder{ResponseWriter: w, status: http.StatusOK}
+
+		route := "unmatched"
+		router.ServeHTTP(rec, r.WithContext(context.WithValue(r.Context(), routeKey{}, &route)))
+
+		metrics.HTTPRequestDuration.
+			WithLabelValues(r.Method, route, strconv.Itoa(rec.status)).
+			Observe(time.Since(start).Seconds())
+	})
+}
+
+// recordRoute runs only for matched routes and hands their template to Metrics
+func recordRoute(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if route, ok := r.Context().Value(routeKey{}).(*string); ok {
+			if current := mux.CurrentRoute(r); current != nil {
+				if tpl, err := current.GetPathTemplate(); err == nil {
+					*route = tpl
+				}
+			}
+		}
+		next.ServeHTTP(w, r)
+	})
+}
@@ -0,0 +1,366 @@
+package order
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"time"
+
+	"wb-test/internal/models"
+	"wb-test/internal/validation"
+	httputils "wb-test/pkg/utils/http-utils"
+
+	"github.com/gorilla/mux"
+)
+
+type OrderService interface {
+	GetOrderWithSource(ctx context.Context, orderUID string) (*models.Order, models.ReadSource, error)
+	UpdateOrder(ctx context.Context, order *models.Order) error
+	GetOrderHistory(ctx context.Context, orderUID string, from, to int) (*models.OrderHistory, error)
+	ListOrders(ctx context.Context, filter models.OrderFilter) (*models.OrderPage, error)
+	LookupOrders(ctx context.Context, lookup models.OrderLookup, value string) ([]*models.Order, error)
+}
+
+// SourceHeader tells whether an order was served from cache or the database
+const SourceHeader = "X-Order-Source"
+
+// maxListLimit caps the page size, a missing limit gets the service default
+const maxListLimit = 100
+
+type Handler struct {
+	service OrderService
+}
+
+func NewHandler(service OrderService) *Handler {
+	return &Handler{service: service}
+}
+
+// GetOrder godoc
+//
+//	@Summary	Get order by UID
+//	@Tags		Orders
+//	@Accept		json
+//	@Produce	json
+//	@Security	BearerAuth
+//	@Security	APIKeyAuth
+//	@Param		order_uid	path		string					true	"Order UID"
+//	@Success	200			{object}	models.Order			"order"
+//	@Header		200			{string}	X-Order-Source			"cache or database"
+//	@Failure	401			{object}	httputils.ErrorResponse	"missing, expired or invalid token"
+//	@Failure	403			{object}	httputils.ErrorResponse	"missing role or scope"
+//	@Failure	404			{object}	httputils.ErrorResponse	"order not found"
+//	@Failure	500			{object}	httputils.ErrorResponse	"internal server error"
+//	@Router		/orders/{order_uid} [get]
+func (h *Handler) GetOrder(w http.ResponseWriter, r *http.Request) {
+	orderUID := mux.Vars(r)["order_uid"]
+
+	order, source, err := h.service.GetOrderWithSource(r.Context(), orderUID)
+	if err != nil {
+		if errors.Is(err, models.ErrOrderNotFound) {
+			httputils.WriteResponse(w, http.StatusNotFound, "order not found", err, nil)
+			return
+		}
+		httputils.WriteResponse(w, http.StatusInternalServerError, "internal server error", err, nil)
+		return
+	}
+
+	w.Header().Set(SourceHeader, string(source))
+	httputils.WriteResponse(w, http.StatusOK, "ok", nil, order)
+}
+
+// ListOrders godoc
+//
+//	@Summary		List orders
+//	@Description	Lists orders newest first. Pass next_cursor from the previous page as cursor to continue.
+//	@Tags			Orders
+//	@Accept			json
+//	@Produce		json
+//	@Security		BearerAuth
+//	@Security		APIKeyAuth
+//	@Param			limit				query		int						false	"Page size (default 20, max 100)"
+//	@Param			cursor				query		string					false	"Cursor from the previous page"
+//	@Param			customer_id			query		string					false	"Customer ID"
+//	@Param			track_number		query		string					false	"Track number"
+//	@Param			delivery_service	query		string					false	"Delivery service"
+//	@Param			payment_provider	query		string					false	"Payment provider"
+//	@Param			payment_currency	query		string					false	"Payment currency"
+//	@Param			brand				query		string					false	"Item brand"
+//	@Param			nm_id				query		int						false	"Item nm_id"
+//	@Param			created_from		query		string					false	"Created at or after (RFC 3339)"
+//	@Param			created_to			query		string					false	"Created before (RFC 3339)"
+//	@Success		200					{object}	models.OrderPage		"orders"
+//	@Failure		400					{object}	httputils.ErrorResponse	"invalid query"
+//	@Failure		401					{object}	httputils.ErrorResponse	"missing, expired or invalid token"
+//	@Failure		403					{object}	httputils.ErrorResponse	"missing role or scope"
+//	@Failure		500					{object}	httputils.ErrorResponse	"internal server error"
+//	@Router			/orders [get]
+func (h *Handler) ListOrders(w http.ResponseWriter, r *http.Request) {
+	filter, err := parseOrderFilter(r)
+	if err != nil {
+		httputils.WriteResponse(w, http.StatusBadRequest, "invalid query", err, nil)
+		return
+	}
+
+	page, err := h.service.ListOrders(r.Context(), filter)
+	if err != nil {
+		httputils.WriteResponse(w, http.StatusInternalServerError, "internal server error", err, nil)
+		return
+	}
+
+	httputils.WriteResponse(w, http.StatusOK, "ok", nil, page)
+}
+
+// parseOrderFilter reads listing filters and pagination from the query
+func parseOrderFilter(r *http.Request) (models.OrderFilter, error) {
+	q := r.URL.Query()
+	filter := models.OrderFilter{
+		CustomerID:      q.Get("customer_id"),
+		TrackNumber:     q.Get("track_number"),
+		DeliveryService: q.Get("delivery_service"),
+		PaymentProvider: q.Get("payment_provider"),
+		PaymentCurrency: q.Get("payment_currency"),
+		Brand:           q.Get("brand"),
+	}
+
+	if v := q.Get("limit"); v != "" {
+		n, err := strconv.Atoi(v)
+		if err != nil || n <= 0 || n > maxListLimit {
+			return filter, fmt.Errorf("limit must be between 1 and %d", maxListLimit)
+		}
+		filter.Limit = n
+	}
+	if v := q.Get("cursor"); v != "" {
+		cursor, err := models.DecodeOrderCursor(v)
+		if err != nil {
+			return filter, err
+		}
+		filter.After = cursor
+	}
+	if v := q.Get("nm_id"); v != "" {
+		n, err := strconv.Atoi(v)
+		if err != nil || n <= 0 {
+			return filter, errors.New("nm_id must be a positive integer")
+		}
+		filter.NmID = n
+	}
+	for name, dst := range map[string]*time.Time{
+		"created_from": &filter.CreatedFrom,
+		"created_to":   &filter.CreatedTo,
+	} {
+		if v := q.Get(name); v != "" {
+			t, err := time.Parse(time.RFC3339, v)
+			if err != nil {
+				return filter, fmt.Errorf("%s must be an RFC 3339 timestamp", name)
+			}
+			*dst = t
+		}
+	}
+
+	return filter, nil
+}
+
+// GetOrdersByTrack godoc
+//
+//	@Summary	Get orders by track number
+//	@Tags		Orders
+//	@Accept		json
+//	@Produce	json
+//	@Security	BearerAuth
+//	@Security	APIKeyAuth
+//	@Param		track_number	path		string					true	"Track number"
+//	@Success	200				{array}		models.Order			"orders"
+//	@Failure	401				{object}	httputils.ErrorResponse	"missing, expired or invalid token"
+//	@Failure	403				{object}	httputils.ErrorResponse	"missing role or scope"
+//	@Failure	404				{object}	httputils.ErrorResponse	"order not found"
+//	@Failure	500				{object}	httputils.ErrorResponse	"internal server error"
+//	@Router		/orders/by-track/{track_number} [get]
+func (h *Handler) GetOrdersByTrack(w http.ResponseWriter, r *http.Request) {
+	h.lookupOrders(w, r, models.LookupTrackNumber, mux.Vars(r)["track_number"], true)
+}
+
+// GetOrdersByTransaction godoc
+//
+//	@Summary	Get orders by payment transaction
+//	@Tags		Orders
+//	@Accept		json
+//	@Produce	json
+//	@Security	BearerAuth
+//	@Security	APIKeyAuth
+//	@Param		transaction	path		string					true	"Payment transaction"
+//	@Success	200			{array}		models.Order			"orders"
+//	@Failure	401			{object}	httputils.ErrorResponse	"missing, expired or invalid token"
+//	@Failure	403			{object}	httputils.ErrorResponse	"missing role or scope"
+//	@Failure	404			{object}	httputils.ErrorResponse	"order not found"
+//	@Failure	500			{object}	httputils.ErrorResponse	"internal server error"
+//	@Router		/orders/by-transaction/{transaction} [get]
+func (h *Handler) GetOrdersByTransaction(w http.ResponseWriter, r *http.Request) {
+	h.lookupOrders(w, r, models.LookupTransaction, mux.Vars(r)["transaction"], true)
+}
+
+// GetCustomerOrders godoc
+//
+//	@Summary		Get orders of a customer
+//	@Description	Returns up to 100 most recent orders of the customer.
+//	@Tags			Orders
+//	@Accept			json
+//	@Produce		json
+//	@Security		BearerAuth
+//	@Security		APIKeyAuth
+//	@Param			customer_id	path		string					true	"Customer ID"
+//	@Success		200			{array}		models.Order			"orders"
+//	@Failure		401			{object}	httputils.ErrorResponse	"missing, expired or invalid token"
+//	@Failure		403			{object}	httputils.ErrorResponse	"missing role or scope"
+//	@Failure		500			{object}	httputils.ErrorResponse	"internal server error"
+//	@Router			/customers/{customer_id}/orders [get]
+func (h *Handler) GetCustomerOrders(w http.ResponseWriter, r *http.Request) {
+	h.lookupOrders(w, r, models.LookupCustomer, mux.Vars(r)["customer_id"], false)
+}
+
+// lookupOrders writes the orders found by a secondary key, an empty result is
+// a 404 when notFoundOnEmpty is set
+func (h *Handler) lookupOrders(w http.ResponseWriter, r *http.Request, lookup models.OrderLookup, value string, notFoundOnEmpty bool) {
+	orders, err := h.service.LookupOrders(r.Context(), lookup, value)
+	if err != nil {
+		httputils.WriteResponse(w, http.StatusInternalServerError, "internal server error", err, nil)
+		return
+	}
+	if len(orders) == 0 && notFoundOnEmpty {
+		httputils.WriteResponse(w, http.StatusNotFound, "order not found",
+			fmt.Errorf("%w: %s %s", models.ErrOrderNotFound, lookup, value), nil)
+		return
+	}
+	if orders == nil {
+		orders = []*models.Order{}
+	}
+
+	httputils.WriteResponse(w, http.StatusOK, "ok", nil, orders)
+}
+
+// UpdateOrder godoc
+//
+//	@Summary		Update or create order
+//	@Description	Replaces the order with its delivery, payment and items. A non-zero version must match the stored one.
+//	@Tags			Orders
+//	@Accept			json
+//	@Produce		json
+//	@Security		BearerAuth
+//	@Security		APIKeyAuth
+//	@Param			order_uid	path		string					true	"Order UID"
+//	@Param			order		body		models.Order			true	"Order"
+//	@Success		200			{object}	models.Order			"updated order"
+//	@Failure		400			{object}	httputils.ErrorResponse	"invalid request body"
+//	@Failure		401			{object}	httputils.ErrorResponse	"missing, expired or invalid token"
+//	@Failure		403			{object}	httputils.ErrorResponse	"missing role or scope"
+//	@Failure		409			{object}	httputils.ErrorResponse	"order version mismatch"
+//	@Failure		422			{object}	httputils.ErrorResponse	"invalid order"
+//	@Failure		500			{object}	httputils.ErrorResponse	"internal server error"
+//	@Router			/orders/{order_uid} [put]
+func (h *Handler) UpdateOrder(w http.ResponseWriter, r *http.Request) {
+	orderUID := mux.Vars(r)["order_uid"]
+
+	var order models.Order
+	if err := json.NewDecoder(r.Body).Decode(&order); err != nil {
+		httputils.WriteResponse(w, http.StatusBadRequest, "invalid request body", err, nil)
+		return
+	}
+	if order.OrderUID == "" {
+		order.OrderUID = orderUID
+	}
+	if order.OrderUID != orderUID {
+		httputils.WriteResponse(w, http.StatusBadRequest, "invalid request body",
+			fmt.Errorf("order_uid %q does not match path %q", order.OrderUID, orderUID), nil)
+		return
+	}
+
+	if err := validation.ValidateOrder(&order); err != nil {
+		var verrs validation.Errors
+		if errors.As(err, &verrs) {
+			httputils.WriteErrorDetails(w, http.StatusUnprocessableEntity, "invalid order", verrs.Details())
+			return
+		}
+		httputils.WriteResponse(w, http.StatusUnprocessableEntity, "invalid order", err, nil)
+		return
+	}
+
+	if err := h.service.UpdateOrder(r.Context(), &order); err != nil {
+		if errors.Is(err, models.ErrOrderVersionMismatch) {
+			httputils.WriteResponse(w, http.StatusConflict, "order version mismatch", err, nil)
+			return
+		}
+		httputils.WriteResponse(w, http.StatusInternalServerError, "internal server error", err, nil)
+		return
+	}
+
+	httputils.WriteResponse(w, http.StatusOK, "ok", nil, &order)
+}
+
+// GetOrderHistory godoc
+//
+//	@Summary		Get order history
+//	@Description	Lists stored versions of the order. When from and to are set the field-level diff between them is included.
+//	@Tags			Orders
+//	@Accept			json
+//	@Produce		json
+//	@Security		BearerAuth
+//	@Security		APIKeyAuth
+//	@Param			order_uid	path		string					true	"Order UID"
+//	@Param			from		query		int						false	"Version to diff from"
+//	@Param			to			query		int						false	"Version to diff to"
+//	@Success		200			{object}	models.OrderHistory		"order history"
+//	@Failure		400			{object}	httputils.ErrorResponse	"invalid version"
+//	@Failure		401			{object}	httputils.ErrorResponse	"missing, expired or invalid token"
+//	@Failure		403			{object}	httputils.ErrorResponse	"missing role or scope"
+//	@Failure		404			{object}	httputils.ErrorResponse	"order or version not found"
+//	@Failure		500			{object}	httputils.ErrorResponse	"internal server error"
+//	@Router			/orders/{order_uid}/history [get]
+func (h *Handler) GetOrderHistory(w http.ResponseWriter, r *http.Request) {
+	orderUID := mux.Vars(r)["order_uid"]
+
+	from, err := parseVersion(r, "from")
+	if err != nil {
+		httputils.WriteResponse(w, http.StatusBadRequest, "invalid version", err, nil)
+		return
+	}
+	to, err := parseVersion(r, "to")
+	if err != nil {
+		httputils.WriteResponse(w, http.StatusBadRequest, "invalid version", err, nil)
+		return
+	}
+	if (from == 0) != (to == 0) {
+		httputils.WriteResponse(w, http.StatusBadRequest, "invalid version",
+			errors.New("from and to must be set together"), nil)
+		return
+	}
+
+	history, err := h.service.GetOrderHistory(r.Context(), orderUID, from, to)
+	if err != nil {
+		if errors.Is(err, models.ErrOrderNotFound) {
+			httputils.WriteResponse(w, http.StatusNotFound, "order not found", err, nil)
+			return
+		}
+		if errors.Is(err, models.ErrOrderVersionNotFound) {
+			httputils.WriteResponse(w, http.StatusNotFound, "order version not found", err, nil)
+			return
+		}
+		httputils.WriteResponse(w, http.StatusInternalServerError, "internal server error", err, nil)
+		return
+	}
+
+	httputils.WriteResponse(w, http.StatusOK, "ok", nil, history)
+}
+
+// parseVersion reads an optional positive version from the query, 0 if absent
+func parseVersion(r *http.Request, name string) (int, error) {
+	v := r.URL.Query().Get(name)
+	if v == "" {
+		return 0, nil
+	}
+	n, err := strconv.Atoi(v)
+	if err != nil || n <= 0 {
+		return 0, fmt.Errorf("%s must be a positive integer", name)
+	}
+	return n, nil
+}
@@ -4,26 +4,82 @@ import (
 	"net/http"
 
 	_ "wb-test/api"
+	"wb-test/internal/handlers/middleware"
 
 	"github.com/gorilla/mux"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
 	httpSwagger "github.com/swaggo/http-swagger"
 )
 
-func InitRouter(h *Handler) *mux.Router {
+func InitRouter(h *Handler) http.Handler {
 	router := mux.NewRouter()
 
+	// Record every request, rejected and unmatched ones included
+	metered := middleware.Metrics(router)
+
+	// Every route requires a bearer token or API key unless marked public,
+	// admins pass every role and scope check
+	auth := middleware.NewAuth(h.tokens, h.apiKeys)
+	router.Use(auth.Middleware)
+
 	// Health
 	{
-		router.HandleFunc("/live", h.health.Health).Methods(http.MethodGet)
+		auth.Public(router.HandleFunc("/live", h.health.Health).Methods(http.MethodGet))
+	}
+
+	// Auth
+	{
+		auth.Public(router.HandleFunc("/.well-known/jwks.json", h.auth.JWKS).Methods(http.MethodGet))
+		auth.Public(router.HandleFunc("/auth/login", h.auth.Login).Methods(http.MethodPost))
+		auth.Public(router.HandleFunc("/auth/refresh", h.auth.Refresh).Methods(http.MethodPost))
+		router.HandleFunc("/auth/logout", h.auth.Logout).Methods(http.MethodPost)
+	}
+
+	// Orders
+	{
+		auth.RequireScope(router.HandleFunc("/orders", h.order.ListOrders).Methods(http.MethodGet), middleware.ScopeOrdersRead)
+		auth.RequireScope(router.HandleFunc("/orders/by-track/{track_number}", h.order.GetOrdersByTrack).Methods(http.MethodGet), middleware.ScopeOrdersRead)
+		auth.RequireScope(router.HandleFunc("/orders/by-transaction/{transaction}", h.order.GetOrdersByTransaction).Methods(http.MethodGet), middleware.ScopeOrdersRead)
+		auth.RequireScope(router.HandleFunc("/customers/{customer_id}/orders", h.order.GetCustomerOrders).Methods(http.MethodGet), middleware.ScopeOrdersRead)
+		auth.RequireScope(router.HandleFunc("/orders/{order_uid}", h.order.GetOrder).Methods(http.MethodGet), middleware.ScopeOrdersRead)
+		auth.RequireScope(router.HandleFunc("/orders/{order_uid}", h.order.UpdateOrder).Methods(http.MethodPut), middleware.ScopeOrdersWrite)
+		auth.RequireScope(router.HandleFunc("/orders/{order_uid}/history", h.order.GetOrderHistory).Methods(http.MethodGet), middleware.ScopeOrdersRead)
+	}
+
+	// Dead letters, replaying re-runs order writes so both are admin only
+	{
+		auth.RequireRole(router.HandleFunc("/dead-letters", h.deadletter.List).Methods(http.MethodGet), middleware.RoleAdmin)
+		auth.RequireRole(router.HandleFunc("/dead-letters/{sequence:[0-9]+}/replay", h.deadletter.Replay).Methods(http.MethodPost), middleware.RoleAdmin)
+	}
+
+	// API keys, managing them is admin only
+	{
+		auth.RequireRole(router.HandleFunc("/api-keys", h.apikey.List).Methods(http.MethodGet), middleware.RoleAdmin)
+		auth.RequireRole(router.HandleFunc("/api-keys", h.apikey.Create).Methods(http.MethodPost), middleware.RoleAdmin)
+		auth.RequireRole(router.HandleFunc("/api-keys/{id:[0-9]+}", h.apikey.Revoke).Methods(http.MethodDelete), middleware.RoleAdmin)
+	}
+
+	// UI, the page itself is public and sends the token with its API calls
+	{
+		auth.Public(router.Handle("/", http.RedirectHandler("/ui/", http.StatusFound)).Methods(http.MethodGet))
+		auth.Public(router.PathPrefix("/ui/").Handler(h.ui.Static()).Methods(http.MethodGet))
 	}
 
 	// Swagger
 	{
 		// Redirect /swagger to /swagger/index.html
-		router.Handle("/documentation", http.RedirectHandler("/swagger/index.html", http.StatusMovedPermanently)).Methods(http.MethodGet)
+		auth.Public(router.Handle("/documentation", http.RedirectHandler("/swagger/index.html", http.StatusMovedPermanently)).Methods(http.MethodGet))
 		// Serve Swagger UI
-		router.PathPrefix("/swagger/").Handler(httpSwagger.WrapHandler)
+		auth.Public(router.PathPrefix("/swagger/").Handler(httpSwagger.WrapHandler))
 	}
 
+	return metered
+}
+
+// InitMetricsRouter serves the Prometheus metrics. They are scraped without a
+// token, so they are served on their own port kept off the public network.
+func InitMetricsRouter() *mux.Router {
+	router := mux.NewRouter()
+	router.Handle("/metrics", promhttp.Handler()).Methods(http.MethodGet)
 	return router
 }
@@ -0,0 +1,133 @@
+"use strict";
+
+const form = document.getElementById("search");
+const input = document.getElementById("order-uid");
+const tokenInput = document.getElementById("token");
+const statusLine = document.getElementById("status");
+const orderSection = document.getElementById("order");
+
+function setStatus(text, isError) {
+    statusLine.textContent = text;
+    statusLine.className = isError ? "error" : "";
+    statusLine.hidden = !text;
+}
+
+function fillList(id, rows) {
+    const list = document.getElementById(id);
+    list.replaceChildren();
+    for (const [label, value] of rows) {
+        const dt = document.createElement("dt");
+        dt.textContent = label;
+        const dd = document.createElement("dd");
+        dd.textContent = value ?? "";
+        list.append(dt, dd);
+    }
+}
+
+function fillItems(items) {
+    const body = document.getElementById("items");
+    body.replaceChildren();
+    for (const item of items || []) {
+        const row = document.createElement("tr");
+        const cells = [
+            item.name, item.brand, item.nm_id, item.size,
+            item.price, item.sale + "%", item.total_price, item.status,
+        ];
+        for (const value of cells) {
+            const td = document.createElement("td");
+            td.textContent = value;
+            row.append(td);
+        }
+        body.append(row);
+    }
+}
+
+function formatDate(value) {
+    if (!value) {
+        return "";
+    }
+    return new Date(value).toLocaleString();
+}
+
+function render(order, source) {
+    document.getElementById("order-title").textContent = order.order_uid;
+
+    const badge = document.getElementById("source");
+    badge.textContent = source ? "from " + source : "source unknown";
+    badge.className = "badge " + (source || "");
+
+    fillList("summary", [
+        ["Track number", order.track_number],
+        ["Customer", order.customer_id],
+        ["Delivery service", order.delivery_service],
+        ["Created", formatDate(order.date_created)],
+        ["Locale", order.locale],
+        ["Version", order.version],
+    ]);
+
+    const d = order.delivery || {};
+    fillList("delivery", [
+        ["Name", d.name],
+        ["Phone", d.phone],
+        ["Email", d.email],
+        ["Address", [d.zip, d.region, d.city, d.address].filter(Boolean).join(", ")],
+    ]);
+
+    const p = order.payment || {};
+    fillList("payment", [
+        ["Transaction", p.transaction],
+        ["Provider", p.provider],
+        ["Bank", p.bank],
+        ["Amount", p.amount + " " + (p.currency || "")],
+        ["Goods total", p.goods_total],
+        ["Delivery cost", p.delivery_cost],
+        ["Custom fee", p.custom_fee],
+        ["Paid", p.payment_dt ? new Date(p.payment_dt * 1000).toLocaleString() : ""],
+    ]);
+
+    fillItems(order.items);
+    orderSection.hidden = false;
+}
+
+async function search(orderUID) {
+    orderSection.hidden = true;
+    setStatus("Loading...");
+
+    try {
+        const headers = {};
+        if (tokenInput.value) {
+            headers["Authorization"] = "Bearer " + tokenInput.value.trim();
+        }
+        const resp = await fetch("/orders/" + encodeURIComponent(orderUID), { headers });
+        const body = await resp.json();
+        if (!resp.ok) {
+            setStatus(body.message || "Request failed with status " + resp.status, true);
+            return;
+        }
+        setStatus("");
+        render(body, resp.headers.get("X-Order-Source"));
+    } catch (err) {
+        setStatus("Request failed: " + err.message, true);
+    }
+}
+
+// The token is kept for the browser session so it survives reloads
+tokenInput.value = sessionStorage.getItem("token") || "";
+tokenInput.addEventListener("change", () => {
+    sessionStorage.setItem("token", tokenInput.value.trim());
+});
+
+form.addEventListener("submit", (event) => {
+    event.preventDefault();
+    const orderUID = input.value.trim();
+    if (!orderUID) {
+        return;
+    }
+    history.replaceState(null, "", "#" + encodeURIComponent(orderUID));
+    search(orderUID);
+});
+
+if (location.hash.length > 1) {
+    input.value = decodeURIComponent(location.hash.slice(1));
+    search(input.value);
+}
@@ -0,0 +1,28 @@
+package ui
+
+import (
+	"embed"
+	"io/fs"
+	"net/http"
+)
+
+//go:embed static
+var static embed.FS
+
+type Handler struct {
+	files http.Handler
+}
+
+func NewHandler() *Handler {
+	sub, err := fs.Sub(static, "static")
+	if err != nil {
+		// The embedded tree is fixed at build time
+		panic(err)
+	}
+	return &Handler{files: http.FileServer(http.FS(sub))}
+}
+
+// Static serves the order search page and its assets, mount it under /ui/
+func (h *Handler) Static() http.Handler {
+	return http.StripPrefix("/ui", h.files)
+}
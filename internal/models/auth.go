@@ -0,0 +1,28 @@
+package models
+
+import "time"
+
+// TokenPair is issued on login and on every refresh
+type TokenPair struct {
+	AccessToken  string `json:"access_token"`
+	RefreshToken string `json:"refresh_token"`
+	TokenType    string `json:"token_type"`
+	// ExpiresIn is the access token lifetime in seconds
+	ExpiresIn int `json:"expires_in"`
+}
+
+// RefreshSession is what a refresh token stands for. Tokens rotated from one
+// another share a Family, reusing any of them revokes the whole family.
+type RefreshSession struct {
+	Family    string    `json:"family"`
+	UserID    int       `json:"user_id"`
+	Username  string    `json:"username"`
+	Roles     []string  `json:"roles,omitempty"`
+	Scopes    []string  `json:"scopes,omitempty"`
+	ExpiresAt time.Time `json:"expires_at"`
+}
+
+// RefreshRequest is the body of /auth/refresh and /auth/logout
+type RefreshRequest struct {
+	RefreshToken string `json:"refresh_token"`
+}
@@ -0,0 +1,33 @@
+package models
+
+import "errors"
+
+var (
+	ErrOrderNotFound = errors.New("order not found")
+	// ErrOrderConflict means an order with the same UID but different content already exists
+	ErrOrderConflict = errors.New("order conflicts with an existing order")
+	// ErrOrderVersionMismatch means an update was based on a stale version of the order
+	ErrOrderVersionMismatch = errors.New("order version mismatch")
+	// ErrOrderVersionNotFound means the requested version is not in the order history
+	ErrOrderVersionNotFound = errors.New("order version not found")
+	// ErrInvalidCursor means a listing cursor token could not be decoded
+	ErrInvalidCursor = errors.New("invalid cursor")
+	// ErrInvalidRefreshToken means a refresh token is unknown, expired or revoked
+	ErrInvalidRefreshToken = errors.New("invalid refresh token")
+	// ErrRefreshTokenReused means an already rotated refresh token was presented again
+	ErrRefreshTokenReused = errors.New("refresh token reused")
+	// ErrUserNotFound is returned by user storage for unknown usernames
+	ErrUserNotFound = errors.New("user not found")
+	// ErrUserExists means the username is already taken
+	ErrUserExists = errors.New("user already exists")
+	// ErrInvalidPassword means a new password does not meet the requirements
+	ErrInvalidPassword = errors.New("invalid password")
+	// ErrInvalidCredentials means the username or password is wrong, callers never learn which
+	ErrInvalidCredentials = errors.New("invalid username or password")
+	// ErrUserLocked means logins are refused after too many failures
+	ErrUserLocked = errors.New("user locked")
+	// ErrAPIKeyNotFound is returned for unknown API key IDs
+	ErrAPIKeyNotFound = errors.New("api key not found")
+	// ErrInvalidAPIKey means an API key is unknown, revoked or expired
+	ErrInvalidAPIKey = errors.New("invalid api key")
+)
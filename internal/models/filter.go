@@ -0,0 +1,59 @@
+package models
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"time"
+)
+
+// OrderFilter selects orders for listing. Empty fields do not filter,
+// CreatedFrom is inclusive and CreatedTo exclusive.
+type OrderFilter struct {
+	CustomerID         string
+	TrackNumber        string
+	DeliveryService    string
+	PaymentProvider    string
+	PaymentCurrency    string
+	PaymentTransaction string
+	Brand              string
+	NmID               int
+	CreatedFrom        time.Time
+	CreatedTo          time.Time
+
+	// After continues the listing past the given position
+	After *OrderCursor
+	Limit int
+}
+
+// OrderCursor is a position in the listing, ordered by date_created then
+// order_uid, both descending
+type OrderCursor struct {
+	DateCreated time.Time `json:"d"`
+	OrderUID    string    `json:"u"`
+}
+
+// Encode returns the cursor as an opaque URL-safe token
+func (c OrderCursor) Encode() string {
+	data, _ := json.Marshal(c)
+	return base64.RawURLEncoding.EncodeToString(data)
+}
+
+// DecodeOrderCursor parses a token returned by OrderCursor.Encode
+func DecodeOrderCursor(token string) (*OrderCursor, error) {
+	data, err := base64.RawURLEncoding.DecodeString(token)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidCursor, err)
+	}
+	var c OrderCursor
+	if err := json.Unmarshal(data, &c); err != nil || c.OrderUID == "" {
+		return nil, ErrInvalidCursor
+	}
+	return &c, nil
+}
+
+// OrderPage is one page of an order listing, NextCursor is empty on the last page
+type OrderPage struct {
+	Orders     []*Order `json:"orders"`
+	NextCursor string   `json:"next_cursor,omitempty"`
+}
@@ -0,0 +1,23 @@
+package models
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+)
+
+// ContentHash returns a SHA-256 of the order's JSON encoding, used to tell a
+// redelivered order from a different order re-submitted under the same UID.
+// Version is not part of the content.
+func (o *Order) ContentHash() (string, error) {
+	content := *o
+	content.Version = 0
+
+	data, err := json.Marshal(content)
+	if err != nil {
+		return "", err
+	}
+
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:]), nil
+}
@@ -0,0 +1,40 @@
+package models
+
+import "time"
+
+// Sources of order writes recorded in the history
+const (
+	SourceCreate     = "create"
+	SourceBulkCreate = "bulk_create"
+	SourceUpdate     = "update"
+)
+
+// OrderVersion is a snapshot of an order as it was written
+type OrderVersion struct {
+	Version   int       `json:"version"`
+	Source    string    `json:"source"`
+	CreatedAt time.Time `json:"created_at"`
+	Order     Order     `json:"order"`
+}
+
+// FieldChange is a single field that differs between two order versions
+type FieldChange struct {
+	Field string `json:"field"`
+	From  any    `json:"from"`
+	To    any    `json:"to"`
+}
+
+// OrderDiff lists field-level changes between two order versions
+type OrderDiff struct {
+	From    int           `json:"from"`
+	To      int           `json:"to"`
+	Changes []FieldChange `json:"changes"`
+}
+
+// OrderHistory lists all stored versions of an order, Diff is set when two
+// versions were requested for comparison
+type OrderHistory struct {
+	OrderUID string         `json:"order_uid"`
+	Versions []OrderVersion `json:"versions"`
+	Diff     *OrderDiff     `json:"diff,omitempty"`
+}
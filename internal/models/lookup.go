@@ -0,0 +1,40 @@
+package models
+
+// OrderLookup is a secondary key orders can be looked up by
+type OrderLookup string
+
+const (
+	LookupTrackNumber OrderLookup = "track"
+	LookupTransaction OrderLookup = "transaction"
+	LookupCustomer    OrderLookup = "customer"
+)
+
+// OrderLookups lists all secondary keys
+var OrderLookups = []OrderLookup{LookupTrackNumber, LookupTransaction, LookupCustomer}
+
+// LookupValue returns the value of the order for the given secondary key
+func (o *Order) LookupValue(lookup OrderLookup) string {
+	switch lookup {
+	case LookupTrackNumber:
+		return o.TrackNumber
+	case LookupTransaction:
+		return o.Payment.Transaction
+	case LookupCustomer:
+		return o.CustomerID
+	}
+	return ""
+}
+
+// Filter returns a listing filter selecting orders with the given value
+func (l OrderLookup) Filter(value string, limit int) OrderFilter {
+	filter := OrderFilter{Limit: limit}
+	switch l {
+	case LookupTrackNumber:
+		filter.TrackNumber = value
+	case LookupTransaction:
+		filter.PaymentTransaction = value
+	case LookupCustomer:
+		filter.CustomerID = value
+	}
+	return filter
+}
@@ -2,7 +2,8 @@ package models
 
 import "time"
 
-// Order represents the complete order structure
+// Order represents the complete order structure. Version is bumped on every
+// update, a non-zero Version in an update must match the stored one.
 type Order struct {
 	OrderUID          string    `json:"order_uid"`
 	TrackNumber       string    `json:"track_number"`
@@ -18,6 +19,7 @@ type Order struct {
 	SmID              int       `json:"sm_id"`
 	DateCreated       time.Time `json:"date_created"`
 	OofShard          string    `json:"oof_shard"`
+	Version           int       `json:"version"`
 }
 
 // Delivery represents delivery information
@@ -0,0 +1,9 @@
+package models
+
+// ReadSource tells which tier served an order read
+type ReadSource string
+
+const (
+	ReadSourceCache    ReadSource = "cache"
+	ReadSourceDatabase ReadSource = "database"
+)
@@ -0,0 +1,28 @@
+package models
+
+import "time"
+
+// User is an account that can log in and obtain tokens
+type User struct {
+	ID           int       `json:"id"`
+	Username     string    `json:"username"`
+	PasswordHash string    `json:"-"`
+	Roles        []string  `json:"roles"`
+	Scopes       []string  `json:"scopes"`
+	CreatedAt    time.Time `json:"created_at"`
+
+	// FailedAttempts counts failed logins since the last success or lockout
+	FailedAttempts int        `json:"-"`
+	LockedUntil    *time.Time `json:"-"`
+}
+
+// IsLocked reports whether logins are refused because of repeated failures
+func (u *User) IsLocked(now time.Time) bool {
+	return u.LockedUntil != nil && now.Before(*u.LockedUntil)
+}
+
+// LoginRequest is the body of /auth/login
+type LoginRequest struct {
+	Username string `json:"username"`
+	Password string `json:"password"`
+}
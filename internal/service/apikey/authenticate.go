@@ -0,0 +1,47 @@
+package apikey
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"log/slog"
+	"time"
+	"wb-test/internal/models"
+)
+
+// touchInterval limits last-used updates to one write per key and interval
+const touchInterval = time.Minute
+
+// AuthenticateAPIKey returns the key matching the plaintext key, unknown,
+// revoked and expired keys return ErrInvalidAPIKey
+func (s *APIKeyService) AuthenticateAPIKey(plain string) (*models.APIKey, error) {
+	key, err := s.repo.GetAPIKeyByHash(hashKey(plain))
+	if err != nil {
+		if errors.Is(err, models.ErrAPIKeyNotFound) {
+			return nil, models.ErrInvalidAPIKey
+		}
+		return nil, fmt.Errorf("failed to get api key: %w", err)
+	}
+
+	now := time.Now()
+	if !key.IsActive(now) {
+		return nil, models.ErrInvalidAPIKey
+	}
+
+	// Last use is informational, a failed update does not fail the request
+	if key.LastUsedAt == nil || now.Sub(*key.LastUsedAt) >= touchInterval {
+		if err := s.repo.TouchAPIKey(key.ID); err != nil {
+			slog.Error("Failed to update api key last use", "error", err, "api_key_id", key.ID)
+		}
+	}
+
+	return key, nil
+}
+
+// hashKey is the stored form of a key. Keys are random 256 bit values, a
+// plain SHA-256 is enough and keeps lookups to a single indexed query.
+func hashKey(plain string) string {
+	sum := sha256.Sum256([]byte(plain))
+	return hex.EncodeToString(sum[:])
+}
@@ -0,0 +1,64 @@
+package apikey
+
+import (
+	"crypto/rand"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+	"wb-test/internal/models"
+)
+
+const (
+	// keyPrefix makes keys recognizable, e.g. by secret scanners
+	keyPrefix = "wbk_"
+	// displayLength is how much of a key is kept to identify it in listings
+	displayLength = len(keyPrefix) + 8
+)
+
+// CreateAPIKey generates a new key, the returned plaintext key is never stored
+func (s *APIKeyService) CreateAPIKey(name string, scopes []string, expiresAt *time.Time) (*models.CreatedAPIKey, error) {
+	name = strings.TrimSpace(name)
+	if name == "" {
+		return nil, errors.New("name is required")
+	}
+	if expiresAt != nil && !expiresAt.After(time.Now()) {
+		return nil, errors.New("expires_at must be in the future")
+	}
+
+	b := make([]byte, 32)
+	if _, err := rand.Read(b); err != nil {
+		return nil, fmt.Errorf("failed to generate api key: %w", err)
+	}
+	plain := keyPrefix + base64.RawURLEncoding.EncodeToString(b)
+
+	key := models.APIKey{
+		Name:      name,
+		Prefix:    plain[:displayLength],
+		KeyHash:   hashKey(plain),
+		Scopes:    scopes,
+		ExpiresAt: expiresAt,
+	}
+	if err := s.repo.CreateAPIKey(&key); err != nil {
+		return nil, fmt.Errorf("failed to create api key: %w", err)
+	}
+
+	return &models.CreatedAPIKey{APIKey: key, Key: plain}, nil
+}
+
+func (s *APIKeyService) ListAPIKeys() ([]*models.APIKey, error) {
+	keys, err := s.repo.ListAPIKeys()
+	if err != nil {
+		return nil, fmt.Errorf("failed to list api keys: %w", err)
+	}
+	return keys, nil
+}
+
+// RevokeAPIKey makes a key stop working immediately
+func (s *APIKeyService) RevokeAPIKey(id int) error {
+	if err := s.repo.RevokeAPIKey(id); err != nil {
+		return fmt.Errorf("failed to revoke api key: %w", err)
+	}
+	return nil
+}
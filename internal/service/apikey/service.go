@@ -0,0 +1,22 @@
+package apikey
+
+import (
+	"wb-test/internal/models"
+)
+
+type APIKeyRepo interface {
+	CreateAPIKey(key *models.APIKey) error
+	GetAPIKeyByHash(hash string) (*models.APIKey, error)
+	ListAPIKeys() ([]*models.APIKey, error)
+	RevokeAPIKey(id int) error
+	TouchAPIKey(id int) error
+}
+
+// APIKeyService manages API keys of service-to-service callers
+type APIKeyService struct {
+	repo APIKeyRepo
+}
+
+func NewAPIKeyService(repo APIKeyRepo) *APIKeyService {
+	return &APIKeyService{repo: repo}
+}
@@ -0,0 +1,65 @@
+package auth
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"fmt"
+	"time"
+	"wb-test/internal/models"
+	"wb-test/pkg/utils/jwt"
+
+	"github.com/google/uuid"
+)
+
+// IssueTokens starts a new refresh token family for the user
+func (s *AuthService) IssueTokens(userID int, username string, roles, scopes []string) (*models.TokenPair, error) {
+	return s.issue(&models.RefreshSession{
+		Family:   uuid.NewString(),
+		UserID:   userID,
+		Username: username,
+		Roles:    roles,
+		Scopes:   scopes,
+	})
+}
+
+// issue signs an access token and stores a fresh refresh token for the session
+func (s *AuthService) issue(session *models.RefreshSession) (*models.TokenPair, error) {
+	accessToken, err := s.tokens.GenerateJWT(session.UserID, session.Username,
+		jwt.WithTTL(s.accessTTL), jwt.WithRoles(session.Roles...), jwt.WithScopes(session.Scopes...))
+	if err != nil {
+		return nil, fmt.Errorf("failed to generate access token: %w", err)
+	}
+
+	refreshToken, err := newRefreshToken()
+	if err != nil {
+		return nil, err
+	}
+
+	session.ExpiresAt = time.Now().Add(s.refreshTTL)
+	if err := s.store.SaveSession(hashToken(refreshToken), session); err != nil {
+		return nil, fmt.Errorf("failed to save refresh token: %w", err)
+	}
+
+	return &models.TokenPair{
+		AccessToken:  accessToken,
+		RefreshToken: refreshToken,
+		TokenType:    "Bearer",
+		ExpiresIn:    int(s.accessTTL.Seconds()),
+	}, nil
+}
+
+func newRefreshToken() (string, error) {
+	b := make([]byte, 32)
+	if _, err := rand.Read(b); err != nil {
+		return "", fmt.Errorf("failed to generate refresh token: %w", err)
+	}
+	return base64.RawURLEncoding.EncodeToString(b), nil
+}
+
+// hashToken is the storage key of a refresh token, the token itself is never stored
+func hashToken(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
+}
@@ -0,0 +1,21 @@
+package auth
+
+import (
+	"fmt"
+	"wb-test/internal/models"
+)
+
+// Login checks the credentials and issues a token pair carrying the user's
+// roles and scopes
+func (s *AuthService) Login(username, password string) (*models.TokenPair, error) {
+	user, err := s.users.Authenticate(username, password)
+	if err != nil {
+		return nil, err
+	}
+
+	pair, err := s.IssueTokens(user.ID, user.Username, user.Roles, user.Scopes)
+	if err != nil {
+		return nil, fmt.Errorf("failed to issue tokens: %w", err)
+	}
+	return pair, nil
+}
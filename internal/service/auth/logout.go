@@ -0,0 +1,35 @@
+package auth
+
+import (
+	"fmt"
+	"time"
+	"wb-test/pkg/utils/jwt"
+)
+
+// Logout revokes the access token the request was made with and, when
+// given, the family of the user's refresh token
+func (s *AuthService) Logout(claims *jwt.Claims, refreshToken string) error {
+	if claims.ID != "" && claims.ExpiresAt != nil {
+		if err := s.store.DenyToken(claims.ID, time.Until(claims.ExpiresAt.Time)); err != nil {
+			return fmt.Errorf("failed to revoke access token: %w", err)
+		}
+	}
+
+	if refreshToken == "" {
+		return nil
+	}
+
+	session, err := s.store.GetSession(hashToken(refreshToken))
+	if err != nil {
+		return fmt.Errorf("failed to get refresh token: %w", err)
+	}
+	// Unknown tokens and tokens of other users are ignored, logout always succeeds
+	if session == nil || session.UserID != claims.UserID {
+		return nil
+	}
+
+	if err := s.store.RevokeFamily(session.Family, s.refreshTTL); err != nil {
+		return fmt.Errorf("failed to revoke refresh token family: %w", err)
+	}
+	return nil
+}
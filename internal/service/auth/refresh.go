@@ -0,0 +1,58 @@
+package auth
+
+import (
+	"errors"
+	"fmt"
+	"log/slog"
+	"time"
+	"wb-test/internal/models"
+)
+
+// Refresh exchanges a refresh token for a new token pair. The presented token
+// is spent, presenting it again revokes every token of its family, since
+// either the client or an attacker holds a stolen copy. The user is reloaded,
+// so the new tokens carry its current roles and scopes and a deleted or
+// locked user cannot refresh.
+func (s *AuthService) Refresh(refreshToken string) (*models.TokenPair, error) {
+	hash := hashToken(refreshToken)
+
+	session, err := s.store.GetSession(hash)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get refresh token: %w", err)
+	}
+	if session == nil || time.Now().After(session.ExpiresAt) {
+		return nil, models.ErrInvalidRefreshToken
+	}
+
+	revoked, err := s.store.IsFamilyRevoked(session.Family)
+	if err != nil {
+		return nil, fmt.Errorf("failed to check refresh token family: %w", err)
+	}
+	if revoked {
+		return nil, models.ErrInvalidRefreshToken
+	}
+
+	user, err := s.users.GetActiveUser(session.UserID)
+	if err != nil {
+		if errors.Is(err, models.ErrUserNotFound) {
+			return nil, models.ErrInvalidRefreshToken
+		}
+		return nil, err
+	}
+
+	first, err := s.store.MarkUsed(hash, time.Until(session.ExpiresAt))
+	if err != nil {
+		return nil, fmt.Errorf("failed to rotate refresh token: %w", err)
+	}
+	if !first {
+		// Every token of the family expires within refreshTTL from now
+		if err := s.store.RevokeFamily(session.Family, s.refreshTTL); err != nil {
+			return nil, fmt.Errorf("failed to revoke refresh token family: %w", err)
+		}
+		slog.Warn("Refresh token reused, family revoked", "family", session.Family, "user_id", session.UserID)
+		return nil, models.ErrRefreshTokenReused
+	}
+
+	session.Username, session.Roles, session.Scopes = user.Username, user.Roles, user.Scopes
+	return s.issue(session)
+}
@@ -0,0 +1,47 @@
+package auth
+
+import (
+	"time"
+	"wb-test/internal/models"
+	"wb-test/pkg/utils/jwt"
+)
+
+// TokenIssuer signs access tokens
+type TokenIssuer interface {
+	GenerateJWT(userID int, username string, opts ...jwt.Option) (string, error)
+}
+
+// Authenticator checks user credentials and reloads users on refresh
+type Authenticator interface {
+	Authenticate(username, password string) (*models.User, error)
+	GetActiveUser(userID int) (*models.User, error)
+}
+
+type TokenStore interface {
+	SaveSession(hash string, session *models.RefreshSession) error
+	GetSession(hash string) (*models.RefreshSession, error)
+	MarkUsed(hash string, ttl time.Duration) (bool, error)
+	RevokeFamily(family string, ttl time.Duration) error
+	IsFamilyRevoked(family string) (bool, error)
+	DenyToken(jti string, ttl time.Duration) error
+}
+
+// AuthService issues short-lived access tokens paired with long-lived opaque
+// refresh tokens that are rotated on every use
+type AuthService struct {
+	tokens     TokenIssuer
+	store      TokenStore
+	users      Authenticator
+	accessTTL  time.Duration
+	refreshTTL time.Duration
+}
+
+func NewAuthService(tokens TokenIssuer, store TokenStore, users Authenticator, accessTTL, refreshTTL time.Duration) *AuthService {
+	return &AuthService{
+		tokens:     tokens,
+		store:      store,
+		users:      users,
+		accessTTL:  accessTTL,
+		refreshTTL: refreshTTL,
+	}
+}
@@ -0,0 +1,56 @@
+package order
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"wb-test/internal/models"
+)
+
+// GetOrder returns an order by its UID, reading through the cache and
+// falling back to the database on a miss
+func (s *OrderService) GetOrder(ctx context.Context, orderUID string) (*models.Order, error) {
+	order, _, err := s.GetOrderWithSource(ctx, orderUID)
+	return order, err
+}
+
+// GetOrderWithSource is GetOrder that also reports which tier served the order
+func (s *OrderService) GetOrderWithSource(ctx context.Context, orderUID string) (*models.Order, models.ReadSource, error) {
+	order, err := s.cache.GetOrder(ctx, orderUID)
+	if err != nil {
+		// Cache is best-effort, go to the database
+		slog.Error("Failed to get order from cache", "error", err, "order_uid", orderUID)
+	}
+	if order != nil {
+		return order, models.ReadSourceCache, nil
+	}
+
+	// Concurrent misses for the same order share a single database load, it
+	// is traced under the first request but not canceled with it
+	v, err, shared := s.loads.Do(orderUID, func() (interface{}, error) {
+		return s.loadOrder(context.WithoutCancel(ctx), orderUID)
+	})
+	if err != nil {
+		return nil, "", err
+	}
+	if shared {
+		slog.Debug("Order load shared between concurrent requests", "order_uid", orderUID)
+	}
+
+	return v.(*models.Order), models.ReadSourceDatabase, nil
+}
+
+// loadOrder reads an order from the database and back-fills the cache
+func (s *OrderService) loadOrder(ctx context.Context, orderUID string) (*models.Order, error) {
+	order, err := s.repo.GetOrder(ctx, orderUID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get order from database: %w", err)
+	}
+
+	// Back-fill the cache so the next lookup is served from Redis
+	if err := s.cache.SetOrder(ctx, orderUID, order); err != nil {
+		slog.Error("Failed to save order to cache", "error", err, "order_uid", orderUID)
+	}
+
+	return order, nil
+}
@@ -0,0 +1,34 @@
+package order
+
+import (
+	"context"
+	"fmt"
+	"wb-test/internal/models"
+)
+
+// defaultListLimit is the page size of listings that do not set one
+const defaultListLimit = 20
+
+// ListOrders returns one page of orders matching the filter. Listings go
+// straight to the database, the cache only serves lookups by UID.
+func (s *OrderService) ListOrders(ctx context.Context, filter models.OrderFilter) (*models.OrderPage, error) {
+	limit := filter.Limit
+	if limit <= 0 {
+		limit = defaultListLimit
+	}
+
+	// Fetch one extra order to know whether another page follows
+	filter.Limit = limit + 1
+	orders, err := s.repo.ListOrders(ctx, filter)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list orders: %w", err)
+	}
+
+	page := &models.OrderPage{Orders: orders}
+	if len(orders) > limit {
+		page.Orders = orders[:limit]
+		last := page.Orders[limit-1]
+		page.NextCursor = models.OrderCursor{DateCreated: last.DateCreated, OrderUID: last.OrderUID}.Encode()
+	}
+	return page, nil
+}
@@ -0,0 +1,35 @@
+package order
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"wb-test/internal/models"
+)
+
+// maxLookupOrders caps how many orders a secondary key lookup returns
+const maxLookupOrders = 100
+
+// LookupOrders returns the most recent orders sharing a secondary key value,
+// newest first. Hits are served from the cache index without touching the database.
+func (s *OrderService) LookupOrders(ctx context.Context, lookup models.OrderLookup, value string) ([]*models.Order, error) {
+	orders, ok, err := s.cache.GetIndexedOrders(ctx, lookup, value)
+	if err != nil {
+		// Cache is best-effort, go to the database
+		slog.Error("Failed to get orders from cache index", "error", err, "lookup", lookup, "value", value)
+	}
+	if ok {
+		return orders, nil
+	}
+
+	orders, err = s.repo.ListOrders(ctx, lookup.Filter(value, maxLookupOrders))
+	if err != nil {
+		return nil, fmt.Errorf("failed to look up orders in database: %w", err)
+	}
+
+	if err := s.cache.SetIndexedOrders(ctx, lookup, value, orders); err != nil {
+		slog.Error("Failed to save order index to cache", "error", err, "lookup", lookup, "value", value)
+	}
+
+	return orders, nil
+}
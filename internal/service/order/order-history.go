@@ -0,0 +1,73 @@
+package order
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"wb-test/internal/models"
+	"wb-test/pkg/utils"
+)
+
+// GetOrderHistory lists the stored versions of an order. When from and to are
+// both non-zero the field-level diff between those versions is included.
+func (s *OrderService) GetOrderHistory(ctx context.Context, orderUID string, from, to int) (*models.OrderHistory, error) {
+	versions, err := s.repo.GetOrderHistory(ctx, orderUID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get order history: %w", err)
+	}
+
+	history := &models.OrderHistory{OrderUID: orderUID, Versions: versions}
+	if from == 0 && to == 0 {
+		return history, nil
+	}
+
+	diff, err := diffVersions(versions, from, to)
+	if err != nil {
+		return nil, err
+	}
+	history.Diff = diff
+	return history, nil
+}
+
+func diffVersions(versions []models.OrderVersion, from, to int) (*models.OrderDiff, error) {
+	left, err := findVersion(versions, from)
+	if err != nil {
+		return nil, err
+	}
+	right, err := findVersion(versions, to)
+	if err != nil {
+		return nil, err
+	}
+
+	// The version field always differs and is already part of the diff header
+	left.Version, right.Version = 0, 0
+	leftData, err := json.Marshal(left)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode version %d: %w", from, err)
+	}
+	rightData, err := json.Marshal(right)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode version %d: %w", to, err)
+	}
+
+	changes, err := utils.DiffJSON(leftData, rightData)
+	if err != nil {
+		return nil, fmt.Errorf("failed to diff versions: %w", err)
+	}
+
+	diff := &models.OrderDiff{From: from, To: to, Changes: make([]models.FieldChange, 0, len(changes))}
+	for _, c := range changes {
+		diff.Changes = append(diff.Changes, models.FieldChange{Field: c.Path, From: c.From, To: c.To})
+	}
+	return diff, nil
+}
+
+// findVersion returns a copy of the order snapshot with the given version
+func findVersion(versions []models.OrderVersion, version int) (models.Order, error) {
+	for _, v := range versions {
+		if v.Version == version {
+			return v.Order, nil
+		}
+	}
+	return models.Order{}, fmt.Errorf("%w: %d", models.ErrOrderVersionNotFound, version)
+}
@@ -1,23 +1,37 @@
 package order
 
 import (
+	"context"
 	"fmt"
 	"log/slog"
+	"time"
 	"wb-test/internal/models"
+	"wb-test/pkg/tracing"
+	"wb-test/pkg/utils"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
 )
 
 // ProcessOrder handles the business logic for processing an order
-func (s *OrderService) ProcessOrder(order *models.Order) error {
-	// Save order to database
-	if err := s.repo.CreateOrder(order); err != nil {
+func (s *OrderService) ProcessOrder(ctx context.Context, order *models.Order) (err error) {
+	ctx, span := tracer.Start(ctx, "OrderService.ProcessOrder", trace.WithAttributes(attribute.String("order.uid", order.OrderUID)))
+	defer func() { tracing.End(span, err) }()
+
+	// Save order to database, retrying transient failures
+	err = s.withRetry(ctx, func() error {
+		return s.repo.CreateOrder(ctx, order)
+	}, "order_uid", order.OrderUID)
+	if err != nil {
 		return fmt.Errorf("failed to save order to database: %w", err)
 	}
 
 	// Save order to cache
-	if err := s.cache.SetOrder(order.OrderUID, order); err != nil {
+	if err := s.cache.SetOrder(ctx, order.OrderUID, order); err != nil {
 		// Log cache error but don't fail the process
 		slog.Error("Failed to save order to cache", "error", err, "order_uid", order.OrderUID)
 	}
+	s.invalidateIndexes(ctx, order)
 
 	slog.Info("Order processed successfully",
 		"order_uid", order.OrderUID,
@@ -27,3 +41,21 @@ func (s *OrderService) ProcessOrder(order *models.Order) error {
 
 	return nil
 }
+
+// invalidateIndexes drops cached lookups the written orders now belong to
+func (s *OrderService) invalidateIndexes(ctx context.Context, orders ...*models.Order) {
+	if err := s.cache.DeleteOrderIndexes(ctx, orders); err != nil {
+		// Log cache error, stale lookups expire on their own
+		slog.Error("Failed to invalidate order indexes in cache", "error", err, "count", len(orders))
+	}
+}
+
+// withRetry runs a database write under the service backoff, logging each retry
+func (s *OrderService) withRetry(ctx context.Context, fn func() error, logArgs ...any) error {
+	return utils.Retry(ctx, s.retry, fn, func(attempt int, delay time.Duration, err error) {
+		trace.SpanFromContext(ctx).AddEvent("retry", trace.WithAttributes(attribute.Int("attempt", attempt)))
+		slog.Warn("Transient database error, retrying",
+			append([]any{"error", err, "attempt", attempt, "delay", delay}, logArgs...)...,
+		)
+	})
+}
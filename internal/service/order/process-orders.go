@@ -0,0 +1,34 @@
+package order
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"wb-test/internal/models"
+	"wb-test/pkg/tracing"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// ProcessOrders saves a batch of orders in one database transaction
+func (s *OrderService) ProcessOrders(ctx context.Context, orders []*models.Order) (err error) {
+	ctx, span := tracer.Start(ctx, "OrderService.ProcessOrders", trace.WithAttributes(attribute.Int("order.count", len(orders))))
+	defer func() { tracing.End(span, err) }()
+
+	err = s.withRetry(ctx, func() error {
+		return s.repo.CreateOrders(ctx, orders)
+	}, "count", len(orders))
+	if err != nil {
+		return fmt.Errorf("failed to save orders to database: %w", err)
+	}
+
+	if err := s.cache.SetOrders(ctx, orders); err != nil {
+		// Log cache error but don't fail the process
+		slog.Error("Failed to save orders to cache", "error", err, "count", len(orders))
+	}
+	s.invalidateIndexes(ctx, orders...)
+
+	slog.Info("Orders batch processed successfully", "count", len(orders))
+	return nil
+}
@@ -1,29 +1,53 @@
 package order
 
 import (
+	"context"
+	"time"
 	"wb-test/internal/models"
+	"wb-test/pkg/utils"
+
+	"go.opentelemetry.io/otel"
+	"golang.org/x/sync/singleflight"
 )
 
+var tracer = otel.Tracer("wb-test/internal/service/order")
+
 type OrderRepo interface {
-	CreateOrder(order *models.Order) error
-	GetOrder(orderUID string) (*models.Order, error)
+	CreateOrder(ctx context.Context, order *models.Order) error
+	CreateOrders(ctx context.Context, orders []*models.Order) error
+	UpdateOrder(ctx context.Context, order *models.Order) error
+	GetOrder(ctx context.Context, orderUID string) (*models.Order, error)
+	GetOrderHistory(ctx context.Context, orderUID string) ([]models.OrderVersion, error)
+	ListOrders(ctx context.Context, filter models.OrderFilter) ([]*models.Order, error)
+	StreamRecentOrders(ctx context.Context, limit int, since time.Time, batchSize int, fn func([]*models.Order) error) error
 }
 
 type OrderCache interface {
-	GetOrder(orderUID string) (*models.Order, error)
-	SetOrder(orderUID string, order *models.Order) error
+	GetOrder(ctx context.Context, orderUID string) (*models.Order, error)
+	SetOrder(ctx context.Context, orderUID string, order *models.Order) error
+	SetOrders(ctx context.Context, orders []*models.Order) error
+	DeleteOrder(ctx context.Context, orderUID string) error
+	GetIndexedOrders(ctx context.Context, lookup models.OrderLookup, value string) ([]*models.Order, bool, error)
+	SetIndexedOrders(ctx context.Context, lookup models.OrderLookup, value string, orders []*models.Order) error
+	DeleteOrderIndexes(ctx context.Context, orders []*models.Order) error
 }
 
 // OrderServiceImpl implements the OrderService interface
 type OrderService struct {
 	repo  OrderRepo
 	cache OrderCache
+	retry utils.Backoff
+
+	// loads deduplicates in-flight database loads per order UID
+	loads singleflight.Group
 }
 
-// NewOrderService creates a new order service instance
-func NewOrderService(repo OrderRepo, cache OrderCache) *OrderService {
+// NewOrderService creates a new order service instance, transient database
+// errors on writes are retried with the given backoff
+func NewOrderService(repo OrderRepo, cache OrderCache, retry utils.Backoff) *OrderService {
 	return &OrderService{
 		repo:  repo,
 		cache: cache,
+		retry: retry,
 	}
 }
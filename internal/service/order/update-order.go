@@ -0,0 +1,35 @@
+package order
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"wb-test/internal/models"
+	"wb-test/pkg/tracing"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// UpdateOrder replaces a stored order (or creates it) and invalidates its
+// cache entry so the next read picks up the new version
+func (s *OrderService) UpdateOrder(ctx context.Context, order *models.Order) (err error) {
+	ctx, span := tracer.Start(ctx, "OrderService.UpdateOrder", trace.WithAttributes(attribute.String("order.uid", order.OrderUID)))
+	defer func() { tracing.End(span, err) }()
+
+	err = s.withRetry(ctx, func() error {
+		return s.repo.UpdateOrder(ctx, order)
+	}, "order_uid", order.OrderUID)
+	if err != nil {
+		return fmt.Errorf("failed to update order in database: %w", err)
+	}
+
+	if err := s.cache.DeleteOrder(ctx, order.OrderUID); err != nil {
+		// Log cache error, the entry expires on its own
+		slog.Error("Failed to invalidate order in cache", "error", err, "order_uid", order.OrderUID)
+	}
+	s.invalidateIndexes(ctx, order)
+
+	slog.Info("Order updated successfully", "order_uid", order.OrderUID, "version", order.Version)
+	return nil
+}
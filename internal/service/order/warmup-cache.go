@@ -0,0 +1,34 @@
+package order
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"time"
+	"wb-test/internal/models"
+)
+
+// WarmupCache restores the most recent orders from the database into the cache.
+// limit (if positive) caps the number of orders, maxAge (if non-zero) skips
+// older ones, so the cache can be warmed by count, by age or by both.
+func (s *OrderService) WarmupCache(ctx context.Context, limit int, maxAge time.Duration, batchSize int) (int, error) {
+	var since time.Time
+	if maxAge > 0 {
+		since = time.Now().Add(-maxAge)
+	}
+
+	restored := 0
+	err := s.repo.StreamRecentOrders(ctx, limit, since, batchSize, func(orders []*models.Order) error {
+		if err := s.cache.SetOrders(ctx, orders); err != nil {
+			return fmt.Errorf("failed to save orders to cache: %w", err)
+		}
+		restored += len(orders)
+		slog.Debug("Cache warmup batch loaded", "batch_size", len(orders), "restored", restored)
+		return nil
+	})
+	if err != nil {
+		return restored, fmt.Errorf("failed to warm up cache: %w", err)
+	}
+
+	return restored, nil
+}
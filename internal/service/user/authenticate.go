@@ -0,0 +1,69 @@
+package user
+
+import (
+	"errors"
+	"fmt"
+	"log/slog"
+	"sync"
+	"time"
+	"wb-test/internal/models"
+
+	"golang.org/x/crypto/bcrypt"
+)
+
+// dummyHash is compared against for unknown users, so a login takes as long
+// whether the username exists or not
+var dummyHash = sync.OnceValue(func() []byte {
+	hash, _ := bcrypt.GenerateFromPassword([]byte("dummy password"), bcrypt.DefaultCost)
+	return hash
+})
+
+// Authenticate checks the password of a user. Wrong passwords and unknown
+// users both return ErrInvalidCredentials, a locked user gets ErrUserLocked
+// without the password being checked.
+func (s *UserService) Authenticate(username, password string) (*models.User, error) {
+	user, err := s.repo.GetUserByUsername(username)
+	if err != nil {
+		if errors.Is(err, models.ErrUserNotFound) {
+			bcrypt.CompareHashAndPassword(dummyHash(), []byte(password))
+			return nil, models.ErrInvalidCredentials
+		}
+		return nil, fmt.Errorf("failed to get user: %w", err)
+	}
+
+	if user.IsLocked(time.Now()) {
+		return nil, models.ErrUserLocked
+	}
+
+	if err := bcrypt.CompareHashAndPassword([]byte(user.PasswordHash), []byte(password)); err != nil {
+		locked, err := s.repo.RecordLoginFailure(user.ID, s.maxAttempts, s.lockout)
+		if err != nil {
+			return nil, fmt.Errorf("failed to record login failure: %w", err)
+		}
+		if locked {
+			slog.Warn("User locked after repeated login failures", "user_id", user.ID, "lockout", s.lockout)
+		}
+		return nil, models.ErrInvalidCredentials
+	}
+
+	if err := s.repo.RecordLoginSuccess(user.ID); err != nil {
+		return nil, fmt.Errorf("failed to record login success: %w", err)
+	}
+
+	return user, nil
+}
+
+// GetActiveUser returns the user with its current roles and scopes. Unknown
+// users return ErrUserNotFound, locked users ErrUserLocked.
+func (s *UserService) GetActiveUser(userID int) (*models.User, error) {
+	user, err := s.repo.GetUserByID(userID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get user: %w", err)
+	}
+
+	if user.IsLocked(time.Now()) {
+		return nil, models.ErrUserLocked
+	}
+
+	return user, nil
+}
@@ -0,0 +1,63 @@
+package user
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"wb-test/internal/models"
+
+	"golang.org/x/crypto/bcrypt"
+)
+
+const (
+	minPasswordLength = 8
+	// maxPasswordLength is the most bcrypt hashes, longer passwords are rejected rather than truncated
+	maxPasswordLength = 72
+)
+
+// CreateUser stores a new user with a bcrypt hash of the password
+func (s *UserService) CreateUser(username, password string, roles, scopes []string) (*models.User, error) {
+	username = strings.TrimSpace(username)
+	if username == "" {
+		return nil, errors.New("username is required")
+	}
+	if err := validatePassword(password); err != nil {
+		return nil, err
+	}
+
+	hash, err := bcrypt.GenerateFromPassword([]byte(password), bcrypt.DefaultCost)
+	if err != nil {
+		return nil, fmt.Errorf("failed to hash password: %w", err)
+	}
+
+	user := &models.User{
+		Username:     username,
+		PasswordHash: string(hash),
+		Roles:        roles,
+		Scopes:       scopes,
+	}
+	if err := s.repo.CreateUser(user); err != nil {
+		return nil, fmt.Errorf("failed to create user: %w", err)
+	}
+
+	return user, nil
+}
+
+// SetUserRoles replaces the roles and scopes of a user, access tokens issued
+// before keep the old ones until they expire and refreshing picks up the new ones
+func (s *UserService) SetUserRoles(username string, roles, scopes []string) error {
+	if err := s.repo.SetUserRoles(username, roles, scopes); err != nil {
+		return fmt.Errorf("failed to set user roles: %w", err)
+	}
+	return nil
+}
+
+func validatePassword(password string) error {
+	if len(password) < minPasswordLength {
+		return fmt.Errorf("%w: must be at least %d characters", models.ErrInvalidPassword, minPasswordLength)
+	}
+	if len(password) > maxPasswordLength {
+		return fmt.Errorf("%w: must be at most %d bytes", models.ErrInvalidPassword, maxPasswordLength)
+	}
+	return nil
+}
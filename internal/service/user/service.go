@@ -0,0 +1,32 @@
+package user
+
+import (
+	"time"
+	"wb-test/internal/models"
+	"wb-test/pkg/config"
+)
+
+type UserRepo interface {
+	CreateUser(user *models.User) error
+	GetUserByUsername(username string) (*models.User, error)
+	GetUserByID(userID int) (*models.User, error)
+	SetUserRoles(username string, roles, scopes []string) error
+	RecordLoginFailure(userID, maxAttempts int, lockout time.Duration) (bool, error)
+	RecordLoginSuccess(userID int) error
+}
+
+// UserService manages accounts and checks their passwords, repeated failures
+// lock the account for a while
+type UserService struct {
+	repo        UserRepo
+	maxAttempts int
+	lockout     time.Duration
+}
+
+func NewUserService(repo UserRepo, cfg config.AuthConfig) *UserService {
+	return &UserService{
+		repo:        repo,
+		maxAttempts: cfg.LoginMaxAttempts,
+		lockout:     cfg.LoginLockout,
+	}
+}
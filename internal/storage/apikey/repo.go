@@ -0,0 +1,124 @@
+package apikey
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"wb-test/internal/models"
+	"wb-test/pkg/db"
+
+	"github.com/jackc/pgx/v5"
+)
+
+const apiKeyColumns = `id, name, prefix, key_hash, scopes, expires_at, last_used_at, revoked_at, created_at`
+
+type apiKeyRepo struct {
+	db *db.PostgresClient
+}
+
+func NewAPIKeyRepo(db *db.PostgresClient) *apiKeyRepo {
+	return &apiKeyRepo{db: db}
+}
+
+func (r *apiKeyRepo) CreateAPIKey(key *models.APIKey) error {
+	ctx := context.Background()
+
+	scopes := key.Scopes
+	if scopes == nil {
+		scopes = []string{}
+	}
+
+	query := `
+		INSERT INTO api_keys (name, prefix, key_hash, scopes, expires_at)
+		VALUES ($1, $2, $3, $4, $5)
+		RETURNING id, created_at
+	`
+	err := r.db.Pool().QueryRow(ctx, query,
+		key.Name, key.Prefix, key.KeyHash, scopes, key.ExpiresAt,
+	).Scan(&key.ID, &key.CreatedAt)
+	if err != nil {
+		return fmt.Errorf("failed to insert api key: %w", err)
+	}
+
+	return nil
+}
+
+func (r *apiKeyRepo) GetAPIKeyByHash(hash string) (*models.APIKey, error) {
+	ctx := context.Background()
+
+	row := r.db.Pool().QueryRow(ctx, `SELECT `+apiKeyColumns+` FROM api_keys WHERE key_hash = $1`, hash)
+	key, err := scanAPIKey(row)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, models.ErrAPIKeyNotFound
+		}
+		return nil, fmt.Errorf("failed to get api key: %w", err)
+	}
+
+	return key, nil
+}
+
+// ListAPIKeys returns all keys, revoked and expired ones included, newest first
+func (r *apiKeyRepo) ListAPIKeys() ([]*models.APIKey, error) {
+	ctx := context.Background()
+
+	rows, err := r.db.Pool().Query(ctx, `SELECT `+apiKeyColumns+` FROM api_keys ORDER BY id DESC`)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list api keys: %w", err)
+	}
+	defer rows.Close()
+
+	keys := []*models.APIKey{}
+	for rows.Next() {
+		key, err := scanAPIKey(rows)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan api key: %w", err)
+		}
+		keys = append(keys, key)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to iterate api keys: %w", err)
+	}
+
+	return keys, nil
+}
+
+// RevokeAPIKey revokes a key, revoking it again keeps the first revocation time
+func (r *apiKeyRepo) RevokeAPIKey(id int) error {
+	ctx := context.Background()
+
+	tag, err := r.db.Pool().Exec(ctx, `
+		UPDATE api_keys SET revoked_at = COALESCE(revoked_at, NOW())
+		WHERE id = $1
+	`, id)
+	if err != nil {
+		return fmt.Errorf("failed to revoke api key: %w", err)
+	}
+	if tag.RowsAffected() == 0 {
+		return models.ErrAPIKeyNotFound
+	}
+
+	return nil
+}
+
+func (r *apiKeyRepo) TouchAPIKey(id int) error {
+	ctx := context.Background()
+
+	if _, err := r.db.Pool().Exec(ctx, `UPDATE api_keys SET last_used_at = NOW() WHERE id = $1`, id); err != nil {
+		return fmt.Errorf("failed to update api key last use: %w", err)
+	}
+	return nil
+}
+
+func scanAPIKey(row pgx.Row) (*models.APIKey, error) {
+	var key models.APIKey
+	err := row.Scan(
+		&key.ID, &key.Name, &key.Prefix, &key.KeyHash, &key.Scopes,
+		&key.ExpiresAt, &key.LastUsedAt, &key.RevokedAt, &key.CreatedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+	return &key, nil
+}
@@ -0,0 +1,176 @@
+package order
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"time"
+
+	"wb-test/internal/models"
+	"wb-test/pkg/metrics"
+	"wb-test/pkg/tracing"
+	"wb-test/pkg/utils"
+
+	"github.com/jackc/pgx/v5"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// CreateOrders writes a batch of orders in one transaction. Order rows go
+// through a statement batch so existing UIDs are skipped, child rows of the
+// newly inserted orders are written with COPY.
+func (r *orderRepo) CreateOrders(ctx context.Context, orders []*models.Order) (err error) {
+	defer metrics.ObserveDBQuery("create_orders", time.Now())
+	if len(orders) == 0 {
+		return nil
+	}
+
+	ctx, span := tracer.Start(ctx, "orderRepo.CreateOrders", trace.WithAttributes(attribute.Int("order.count", len(orders))))
+	defer func() { tracing.End(span, err) }()
+	defer func() { err = classifyError(err) }()
+
+	tx, err := r.db.Pool().Begin(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback(ctx)
+
+	// Insert orders, existing UIDs are skipped and checked for conflicts below
+	orderQuery := `
+		INSERT INTO orders (
+			order_uid, track_number, entry, locale, internal_signature,
+			customer_id, delivery_service, shard_key, sm_id, date_created, oof_shard, content_hash
+		) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12)
+		ON CONFLICT (order_uid) DO NOTHING
+		RETURNING version
+	`
+	batch := &pgx.Batch{}
+	hashes := make([]string, len(orders))
+	for i, order := range orders {
+		hashes[i], err = order.ContentHash()
+		if err != nil {
+			return utils.Permanent(fmt.Errorf("failed to hash order %s: %w", order.OrderUID, err))
+		}
+		batch.Queue(orderQuery,
+			order.OrderUID, order.TrackNumber, order.Entry, order.Locale,
+			order.InternalSignature, order.CustomerID, order.DeliveryService,
+			order.ShardKey, order.SmID, order.DateCreated, order.OofShard, hashes[i],
+		)
+	}
+
+	results := tx.SendBatch(ctx, batch)
+	inserted := make([]*models.Order, 0, len(orders))
+	skipped := make(map[string]string)
+	for i, order := range orders {
+		var version int
+		err := results.QueryRow().Scan(&version)
+		if err == pgx.ErrNoRows {
+			if prev, ok := skipped[order.OrderUID]; ok && prev != hashes[i] {
+				results.Close()
+				return utils.Permanent(fmt.Errorf("%w: [%s]", models.ErrOrderConflict, order.OrderUID))
+			}
+			skipped[order.OrderUID] = hashes[i]
+			continue
+		}
+		if err != nil {
+			results.Close()
+			return fmt.Errorf("failed to insert order %s: %w", order.OrderUID, err)
+		}
+		order.Version = version
+		inserted = append(inserted, order)
+	}
+	if err := results.Close(); err != nil {
+		return fmt.Errorf("failed to insert orders: %w", err)
+	}
+
+	if len(skipped) > 0 {
+		if err := checkExistingOrders(ctx, tx, skipped); err != nil {
+			return err
+		}
+	}
+
+	// Copy deliveries
+	_, err = tx.CopyFrom(ctx,
+		pgx.Identifier{"deliveries"},
+		[]string{"order_uid", "name", "phone", "zip", "city", "address", "region", "email"},
+		pgx.CopyFromSlice(len(inserted), func(i int) ([]any, error) {
+			o := inserted[i]
+			return []any{
+				o.OrderUID, o.Delivery.Name, o.Delivery.Phone, o.Delivery.Zip,
+				o.Delivery.City, o.Delivery.Address, o.Delivery.Region, o.Delivery.Email,
+			}, nil
+		}),
+	)
+	if err != nil {
+		return fmt.Errorf("failed to copy deliveries: %w", err)
+	}
+
+	// Copy payments
+	_, err = tx.CopyFrom(ctx,
+		pgx.Identifier{"payments"},
+		[]string{
+			"order_uid", "transaction", "request_id", "currency", "provider", "amount",
+			"payment_dt", "bank", "delivery_cost", "goods_total", "custom_fee",
+		},
+		pgx.CopyFromSlice(len(inserted), func(i int) ([]any, error) {
+			o := inserted[i]
+			return []any{
+				o.OrderUID, o.Payment.Transaction, o.Payment.RequestID,
+				o.Payment.Currency, o.Payment.Provider, o.Payment.Amount,
+				o.Payment.PaymentDt, o.Payment.Bank, o.Payment.DeliveryCost,
+				o.Payment.GoodsTotal, o.Payment.CustomFee,
+			}, nil
+		}),
+	)
+	if err != nil {
+		return fmt.Errorf("failed to copy payments: %w", err)
+	}
+
+	// Copy items
+	var itemRows [][]any
+	for _, o := range inserted {
+		for _, item := range o.Items {
+			itemRows = append(itemRows, []any{
+				o.OrderUID, item.ChrtID, item.TrackNumber, item.Price,
+				item.Rid, item.Name, item.Sale, item.Size, item.TotalPrice,
+				item.NmID, item.Brand, item.Status,
+			})
+		}
+	}
+	_, err = tx.CopyFrom(ctx,
+		pgx.Identifier{"items"},
+		[]string{
+			"order_uid", "chrt_id", "track_number", "price", "rid", "name", "sale",
+			"size", "total_price", "nm_id", "brand", "status",
+		},
+		pgx.CopyFromRows(itemRows),
+	)
+	if err != nil {
+		return fmt.Errorf("failed to copy items: %w", err)
+	}
+
+	// Copy history snapshots
+	_, err = tx.CopyFrom(ctx,
+		pgx.Identifier{"order_history"},
+		[]string{"order_uid", "version", "source", "snapshot"},
+		pgx.CopyFromSlice(len(inserted), func(i int) ([]any, error) {
+			o := inserted[i]
+			snapshot, err := orderSnapshot(o, o.Version)
+			if err != nil {
+				return nil, err
+			}
+			return []any{o.OrderUID, o.Version, models.SourceBulkCreate, snapshot}, nil
+		}),
+	)
+	if err != nil {
+		return fmt.Errorf("failed to copy order history: %w", err)
+	}
+
+	// Commit transaction
+	if err = tx.Commit(ctx); err != nil {
+		return fmt.Errorf("failed to commit transaction: %w", err)
+	}
+
+	slog.Info("Orders saved to database", "received", len(orders), "inserted", len(inserted))
+	return nil
+}
@@ -0,0 +1,72 @@
+package order
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+
+	"wb-test/internal/models"
+	"wb-test/pkg/utils"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+)
+
+// classifyError marks database errors that retrying cannot fix as permanent.
+// Connection failures, serialization failures, deadlocks and resource
+// exhaustion stay transient.
+func classifyError(err error) error {
+	var pgErr *pgconn.PgError
+	if !errors.As(err, &pgErr) || len(pgErr.Code) < 2 {
+		return err
+	}
+
+	switch pgErr.Code[:2] {
+	case "22", // data exception
+		"23", // integrity constraint violation
+		"42": // syntax error or access rule violation
+		return utils.Permanent(err)
+	}
+
+	return err
+}
+
+// checkExistingOrders compares content hashes of orders whose UIDs already
+// exist. Exact duplicates are skipped, a different hash is a conflict.
+func checkExistingOrders(ctx context.Context, tx pgx.Tx, hashes map[string]string) error {
+	uids := make([]string, 0, len(hashes))
+	for uid := range hashes {
+		uids = append(uids, uid)
+	}
+
+	rows, err := tx.Query(ctx, `SELECT order_uid, content_hash FROM orders WHERE order_uid = ANY($1)`, uids)
+	if err != nil {
+		return fmt.Errorf("failed to query existing orders: %w", err)
+	}
+	defer rows.Close()
+
+	var conflicts []string
+	for rows.Next() {
+		var uid string
+		var existing *string
+		if err := rows.Scan(&uid, &existing); err != nil {
+			return fmt.Errorf("failed to scan existing order: %w", err)
+		}
+
+		// Orders stored before hashing was introduced cannot be compared
+		if existing == nil || *existing == hashes[uid] {
+			slog.Info("Duplicate order skipped", "order_uid", uid)
+			continue
+		}
+		conflicts = append(conflicts, uid)
+	}
+	if err := rows.Err(); err != nil {
+		return fmt.Errorf("failed to iterate existing orders: %w", err)
+	}
+
+	if len(conflicts) > 0 {
+		return utils.Permanent(fmt.Errorf("%w: %v", models.ErrOrderConflict, conflicts))
+	}
+	return nil
+}
@@ -0,0 +1,88 @@
+package order
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"wb-test/internal/models"
+	"wb-test/pkg/metrics"
+	"wb-test/pkg/utils"
+
+	"github.com/jackc/pgx/v5"
+)
+
+// appendHistory records a snapshot of the order as written with the given version
+func appendHistory(ctx context.Context, tx pgx.Tx, order *models.Order, version int, source string) error {
+	snapshot, err := orderSnapshot(order, version)
+	if err != nil {
+		return err
+	}
+
+	historyQuery := `
+		INSERT INTO order_history (order_uid, version, source, snapshot)
+		VALUES ($1, $2, $3, $4)
+	`
+	if _, err := tx.Exec(ctx, historyQuery, order.OrderUID, version, source, snapshot); err != nil {
+		return fmt.Errorf("failed to insert order history: %w", err)
+	}
+	return nil
+}
+
+// orderSnapshot encodes a copy of the order carrying the written version
+func orderSnapshot(order *models.Order, version int) ([]byte, error) {
+	snapshot := *order
+	snapshot.Version = version
+	data, err := json.Marshal(snapshot)
+	if err != nil {
+		return nil, utils.Permanent(fmt.Errorf("failed to encode order snapshot: %w", err))
+	}
+	return data, nil
+}
+
+// GetOrderHistory returns all stored versions of an order, oldest first.
+// Orders written before history was kept have an empty history.
+func (r *orderRepo) GetOrderHistory(ctx context.Context, orderUID string) ([]models.OrderVersion, error) {
+	defer metrics.ObserveDBQuery("get_order_history", time.Now())
+
+	historyQuery := `
+		SELECT version, source, snapshot, created_at
+		FROM order_history WHERE order_uid = $1
+		ORDER BY version, id
+	`
+	rows, err := r.db.Pool().Query(ctx, historyQuery, orderUID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query order history: %w", err)
+	}
+	defer rows.Close()
+
+	versions := make([]models.OrderVersion, 0)
+	for rows.Next() {
+		var v models.OrderVersion
+		var snapshot []byte
+		if err := rows.Scan(&v.Version, &v.Source, &snapshot, &v.CreatedAt); err != nil {
+			return nil, fmt.Errorf("failed to scan order history: %w", err)
+		}
+		if err := json.Unmarshal(snapshot, &v.Order); err != nil {
+			return nil, fmt.Errorf("failed to decode order snapshot: %w", err)
+		}
+		versions = append(versions, v)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to iterate order history: %w", err)
+	}
+
+	if len(versions) == 0 {
+		var exists bool
+		err := r.db.Pool().QueryRow(ctx, "SELECT EXISTS (SELECT 1 FROM orders WHERE order_uid = $1)", orderUID).Scan(&exists)
+		if err != nil {
+			return nil, fmt.Errorf("failed to query order: %w", err)
+		}
+		if !exists {
+			return nil, fmt.Errorf("%w: %s", models.ErrOrderNotFound, orderUID)
+		}
+	}
+
+	return versions, nil
+}
@@ -0,0 +1,119 @@
+package order
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"wb-test/internal/models"
+	"wb-test/pkg/metrics"
+)
+
+// ListOrders returns up to filter.Limit orders matching the filter, newest
+// first. Pagination is keyset based on (date_created, order_uid).
+func (r *orderRepo) ListOrders(ctx context.Context, filter models.OrderFilter) ([]*models.Order, error) {
+	defer metrics.ObserveDBQuery("list_orders", time.Now())
+
+	var conds []string
+	var args []any
+	add := func(cond string, arg any) {
+		args = append(args, arg)
+		conds = append(conds, fmt.Sprintf(cond, len(args)))
+	}
+
+	if filter.CustomerID != "" {
+		add("o.customer_id = $%d", filter.CustomerID)
+	}
+	if filter.TrackNumber != "" {
+		add("o.track_number = $%d", filter.TrackNumber)
+	}
+	if filter.DeliveryService != "" {
+		add("o.delivery_service = $%d", filter.DeliveryService)
+	}
+	if filter.PaymentProvider != "" {
+		add("EXISTS (SELECT 1 FROM payments p WHERE p.order_uid = o.order_uid AND p.provider = $%d)", filter.PaymentProvider)
+	}
+	if filter.PaymentCurrency != "" {
+		add("EXISTS (SELECT 1 FROM payments p WHERE p.order_uid = o.order_uid AND p.currency = $%d)", filter.PaymentCurrency)
+	}
+	if filter.PaymentTransaction != "" {
+		add("EXISTS (SELECT 1 FROM payments p WHERE p.order_uid = o.order_uid AND p.transaction = $%d)", filter.PaymentTransaction)
+	}
+	if filter.Brand != "" {
+		add("EXISTS (SELECT 1 FROM items i WHERE i.order_uid = o.order_uid AND i.brand = $%d)", filter.Brand)
+	}
+	if filter.NmID != 0 {
+		add("EXISTS (SELECT 1 FROM items i WHERE i.order_uid = o.order_uid AND i.nm_id = $%d)", filter.NmID)
+	}
+	if !filter.CreatedFrom.IsZero() {
+		add("o.date_created >= $%d", filter.CreatedFrom)
+	}
+	if !filter.CreatedTo.IsZero() {
+		add("o.date_created < $%d", filter.CreatedTo)
+	}
+	if filter.After != nil {
+		args = append(args, filter.After.DateCreated, filter.After.OrderUID)
+		conds = append(conds, fmt.Sprintf("(o.date_created, o.order_uid) < ($%d, $%d)", len(args)-1, len(args)))
+	}
+
+	where := ""
+	if len(conds) > 0 {
+		where = "WHERE " + strings.Join(conds, " AND ")
+	}
+	args = append(args, filter.Limit)
+
+	ordersQuery := fmt.Sprintf(`
+		SELECT o.order_uid, o.track_number, o.entry, o.locale, o.internal_signature,
+			   o.customer_id, o.delivery_service, o.shard_key, o.sm_id, o.date_created, o.oof_shard, o.version
+		FROM orders o
+		%s
+		ORDER BY o.date_created DESC, o.order_uid DESC
+		LIMIT $%d
+	`, where, len(args))
+
+	// Headers and details are read from one snapshot, so no order is returned
+	// half-written
+	tx, err := r.db.Pool().BeginTx(ctx, snapshotRead)
+	if err != nil {
+		return nil, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback(ctx)
+
+	rows, err := tx.Query(ctx, ordersQuery, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query orders: %w", err)
+	}
+
+	orders := make([]*models.Order, 0, filter.Limit)
+	for rows.Next() {
+		var order models.Order
+		err := rows.Scan(
+			&order.OrderUID, &order.TrackNumber, &order.Entry, &order.Locale,
+			&order.InternalSignature, &order.CustomerID, &order.DeliveryService,
+			&order.ShardKey, &order.SmID, &order.DateCreated, &order.OofShard, &order.Version,
+		)
+		if err != nil {
+			rows.Close()
+			return nil, fmt.Errorf("failed to scan order: %w", err)
+		}
+		orders = append(orders, &order)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to iterate orders: %w", err)
+	}
+
+	if len(orders) == 0 {
+		return orders, nil
+	}
+	if err := loadOrderDetails(ctx, tx, orders); err != nil {
+		return nil, err
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return nil, fmt.Errorf("failed to commit transaction: %w", err)
+	}
+
+	return orders, nil
+}
@@ -0,0 +1,223 @@
+package order
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"wb-test/internal/models"
+
+	"github.com/jackc/pgx/v5"
+)
+
+// warmupBatchSize is the page size used when neither a batch size nor a limit is set
+const warmupBatchSize = 100
+
+// StreamRecentOrders loads up to limit most recent orders created after since
+// and passes them to fn in batches of batchSize. A limit of zero or less means
+// no count cap, a zero since means no lower bound. Orders are read one batch
+// at a time, keyset paginated on (date_created, order_uid).
+func (r *orderRepo) StreamRecentOrders(ctx context.Context, limit int, since time.Time, batchSize int, fn func([]*models.Order) error) error {
+	if batchSize <= 0 {
+		batchSize = warmupBatchSize
+		if limit > 0 {
+			batchSize = limit
+		}
+	}
+
+	var sinceArg *time.Time
+	if !since.IsZero() {
+		sinceArg = &since
+	}
+
+	var after *models.OrderCursor
+	remaining := limit
+	for limit <= 0 || remaining > 0 {
+		pageSize := batchSize
+		if limit > 0 {
+			pageSize = min(batchSize, remaining)
+		}
+
+		batch, err := r.recentOrdersPage(ctx, sinceArg, after, pageSize)
+		if err != nil {
+			return err
+		}
+		if len(batch) == 0 {
+			return nil
+		}
+
+		if err := fn(batch); err != nil {
+			return err
+		}
+
+		if len(batch) < pageSize {
+			return nil
+		}
+		remaining -= len(batch)
+		last := batch[len(batch)-1]
+		after = &models.OrderCursor{DateCreated: last.DateCreated, OrderUID: last.OrderUID}
+	}
+
+	return nil
+}
+
+// recentOrdersPage reads up to pageSize orders created after since, continuing
+// past the after cursor when it is set. Headers and details of a page are read
+// from one snapshot, so no order is returned half-written.
+func (r *orderRepo) recentOrdersPage(ctx context.Context, since *time.Time, after *models.OrderCursor, pageSize int) ([]*models.Order, error) {
+	ordersQuery := `
+		SELECT order_uid, track_number, entry, locale, internal_signature,
+			   customer_id, delivery_service, shard_key, sm_id, date_created, oof_shard, version
+		FROM orders
+		WHERE ($1::timestamptz IS NULL OR date_created >= $1)
+		  AND ($2::timestamptz IS NULL OR (date_created, order_uid) < ($2, $3))
+		ORDER BY date_created DESC, order_uid DESC
+		LIMIT $4
+	`
+	var afterDate *time.Time
+	var afterUID string
+	if after != nil {
+		afterDate = &after.DateCreated
+		afterUID = after.OrderUID
+	}
+
+	tx, err := r.db.Pool().BeginTx(ctx, snapshotRead)
+	if err != nil {
+		return nil, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback(ctx)
+
+	rows, err := tx.Query(ctx, ordersQuery, since, afterDate, afterUID, pageSize)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query recent orders: %w", err)
+	}
+	defer rows.Close()
+
+	orders := make([]*models.Order, 0, pageSize)
+	for rows.Next() {
+		var order models.Order
+		err := rows.Scan(
+			&order.OrderUID, &order.TrackNumber, &order.Entry, &order.Locale,
+			&order.InternalSignature, &order.CustomerID, &order.DeliveryService,
+			&order.ShardKey, &order.SmID, &order.DateCreated, &order.OofShard, &order.Version,
+		)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan order: %w", err)
+		}
+		orders = append(orders, &order)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to iterate recent orders: %w", err)
+	}
+	rows.Close()
+
+	if len(orders) == 0 {
+		return orders, nil
+	}
+	if err := loadOrderDetails(ctx, tx, orders); err != nil {
+		return nil, err
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return nil, fmt.Errorf("failed to commit transaction: %w", err)
+	}
+
+	return orders, nil
+}
+
+// snapshotRead runs every statement of a read transaction against the same
+// snapshot
+var snapshotRead = pgx.TxOptions{IsoLevel: pgx.RepeatableRead, AccessMode: pgx.ReadOnly}
+
+// loadOrderDetails fills delivery, payment and items for a batch of orders,
+// tx must be a snapshotRead transaction for them to match the headers
+func loadOrderDetails(ctx context.Context, tx pgx.Tx, orders []*models.Order) error {
+	byUID := make(map[string]*models.Order, len(orders))
+	uids := make([]string, 0, len(orders))
+	for _, order := range orders {
+		byUID[order.OrderUID] = order
+		uids = append(uids, order.OrderUID)
+	}
+
+	// Query deliveries
+	deliveryQuery := `
+		SELECT order_uid, name, phone, zip, city, address, region, email
+		FROM deliveries WHERE order_uid = ANY($1)
+	`
+	rows, err := tx.Query(ctx, deliveryQuery, uids)
+	if err != nil {
+		return fmt.Errorf("failed to query deliveries: %w", err)
+	}
+	for rows.Next() {
+		var uid string
+		var d models.Delivery
+		if err := rows.Scan(&uid, &d.Name, &d.Phone, &d.Zip, &d.City, &d.Address, &d.Region, &d.Email); err != nil {
+			rows.Close()
+			return fmt.Errorf("failed to scan delivery: %w", err)
+		}
+		byUID[uid].Delivery = d
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return fmt.Errorf("failed to iterate deliveries: %w", err)
+	}
+
+	// Query payments
+	paymentQuery := `
+		SELECT order_uid, transaction, request_id, currency, provider, amount,
+			   payment_dt, bank, delivery_cost, goods_total, custom_fee
+		FROM payments WHERE order_uid = ANY($1)
+	`
+	rows, err = tx.Query(ctx, paymentQuery, uids)
+	if err != nil {
+		return fmt.Errorf("failed to query payments: %w", err)
+	}
+	for rows.Next() {
+		var uid string
+		var p models.Payment
+		err := rows.Scan(
+			&uid, &p.Transaction, &p.RequestID, &p.Currency, &p.Provider, &p.Amount,
+			&p.PaymentDt, &p.Bank, &p.DeliveryCost, &p.GoodsTotal, &p.CustomFee,
+		)
+		if err != nil {
+			rows.Close()
+			return fmt.Errorf("failed to scan payment: %w", err)
+		}
+		byUID[uid].Payment = p
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return fmt.Errorf("failed to iterate payments: %w", err)
+	}
+
+	// Query items
+	itemsQuery := `
+		SELECT order_uid, chrt_id, track_number, price, rid, name, sale,
+			   size, total_price, nm_id, brand, status
+		FROM items WHERE order_uid = ANY($1)
+		ORDER BY id
+	`
+	rows, err = tx.Query(ctx, itemsQuery, uids)
+	if err != nil {
+		return fmt.Errorf("failed to query items: %w", err)
+	}
+	defer rows.Close()
+	for rows.Next() {
+		var uid string
+		var item models.Item
+		err := rows.Scan(
+			&uid, &item.ChrtID, &item.TrackNumber, &item.Price, &item.Rid,
+			&item.Name, &item.Sale, &item.Size, &item.TotalPrice,
+			&item.NmID, &item.Brand, &item.Status,
+		)
+		if err != nil {
+			return fmt.Errorf("failed to scan item: %w", err)
+		}
+		byUID[uid].Items = append(byUID[uid].Items, item)
+	}
+	if err := rows.Err(); err != nil {
+		return fmt.Errorf("failed to iterate items: %w", err)
+	}
+
+	return nil
+}
@@ -2,15 +2,25 @@ package order
 
 import (
 	"context"
+	"encoding/json"
 	"fmt"
 	"log/slog"
+	"time"
 
 	"wb-test/internal/models"
 	"wb-test/pkg/db"
+	"wb-test/pkg/metrics"
+	"wb-test/pkg/tracing"
+	"wb-test/pkg/utils"
 
 	"github.com/jackc/pgx/v5"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
 )
 
+var tracer = otel.Tracer("wb-test/internal/storage/order")
+
 type orderRepo struct {
 	db *db.PostgresClient
 }
@@ -19,8 +29,11 @@ func NewOrderRepo(db *db.PostgresClient) *orderRepo {
 	return &orderRepo{db: db}
 }
 
-func (r *orderRepo) CreateOrder(order *models.Order) error {
-	ctx := context.Background()
+func (r *orderRepo) CreateOrder(ctx context.Context, order *models.Order) (err error) {
+	defer metrics.ObserveDBQuery("create_order", time.Now())
+	ctx, span := tracer.Start(ctx, "orderRepo.CreateOrder", trace.WithAttributes(attribute.String("order.uid", order.OrderUID)))
+	defer func() { tracing.End(span, err) }()
+	defer func() { err = classifyError(err) }()
 
 	// Start a transaction
 	tx, err := r.db.Pool().Begin(ctx)
@@ -29,30 +42,59 @@ func (r *orderRepo) CreateOrder(order *models.Order) error {
 	}
 	defer tx.Rollback(ctx)
 
-	// Insert order
+	hash, err := order.ContentHash()
+	if err != nil {
+		return utils.Permanent(fmt.Errorf("failed to hash order: %w", err))
+	}
+
+	// Insert order, an existing UID means a redelivery or a conflicting re-submission
 	orderQuery := `
 		INSERT INTO orders (
-			order_uid, track_number, entry, locale, internal_signature, 
-			customer_id, delivery_service, shard_key, sm_id, date_created, oof_shard
-		) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11)
+			order_uid, track_number, entry, locale, internal_signature,
+			customer_id, delivery_service, shard_key, sm_id, date_created, oof_shard, content_hash
+		) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12)
 		ON CONFLICT (order_uid) DO NOTHING
+		RETURNING version
 	`
-	_, err = tx.Exec(ctx, orderQuery,
+	var version int
+	err = tx.QueryRow(ctx, orderQuery,
 		order.OrderUID, order.TrackNumber, order.Entry, order.Locale,
 		order.InternalSignature, order.CustomerID, order.DeliveryService,
-		order.ShardKey, order.SmID, order.DateCreated, order.OofShard,
-	)
+		order.ShardKey, order.SmID, order.DateCreated, order.OofShard, hash,
+	).Scan(&version)
+	if err == pgx.ErrNoRows {
+		return checkExistingOrders(ctx, tx, map[string]string{order.OrderUID: hash})
+	}
 	if err != nil {
 		return fmt.Errorf("failed to insert order: %w", err)
 	}
 
+	if err := insertOrderDetails(ctx, tx, order); err != nil {
+		return err
+	}
+	if err := appendHistory(ctx, tx, order, version, models.SourceCreate); err != nil {
+		return err
+	}
+
+	// Commit transaction
+	if err = tx.Commit(ctx); err != nil {
+		return fmt.Errorf("failed to commit transaction: %w", err)
+	}
+
+	order.Version = version
+	slog.Info("Order saved to database", "order_uid", order.OrderUID)
+	return nil
+}
+
+// insertOrderDetails inserts delivery, payment and items of an order
+func insertOrderDetails(ctx context.Context, tx pgx.Tx, order *models.Order) error {
 	// Insert delivery
 	deliveryQuery := `
 		INSERT INTO deliveries (
 			order_uid, name, phone, zip, city, address, region, email
 		) VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
 	`
-	_, err = tx.Exec(ctx, deliveryQuery,
+	_, err := tx.Exec(ctx, deliveryQuery,
 		order.OrderUID, order.Delivery.Name, order.Delivery.Phone, order.Delivery.Zip,
 		order.Delivery.City, order.Delivery.Address, order.Delivery.Region, order.Delivery.Email,
 	)
@@ -95,91 +137,52 @@ func (r *orderRepo) CreateOrder(order *models.Order) error {
 		}
 	}
 
-	// Commit transaction
-	if err = tx.Commit(ctx); err != nil {
-		return fmt.Errorf("failed to commit transaction: %w", err)
-	}
-
-	slog.Info("Order saved to database", "order_uid", order.OrderUID)
 	return nil
 }
 
-func (r *orderRepo) GetOrder(orderUID string) (*models.Order, error) {
-	ctx := context.Background()
+func (r *orderRepo) GetOrder(ctx context.Context, orderUID string) (*models.Order, error) {
+	defer metrics.ObserveDBQuery("get_order", time.Now())
 
-	// Query order
+	// Assemble the whole order in a single statement, so it is read from one
+	// snapshot and never observed half-written
 	orderQuery := `
-		SELECT order_uid, track_number, entry, locale, internal_signature,
-			   customer_id, delivery_service, shard_key, sm_id, date_created, oof_shard
-		FROM orders WHERE order_uid = $1
+		SELECT o.order_uid, o.track_number, o.entry, o.locale, o.internal_signature,
+			   o.customer_id, o.delivery_service, o.shard_key, o.sm_id, o.date_created, o.oof_shard, o.version,
+			   (SELECT to_jsonb(d) FROM deliveries d WHERE d.order_uid = o.order_uid ORDER BY d.id LIMIT 1),
+			   (SELECT to_jsonb(p) FROM payments p WHERE p.order_uid = o.order_uid ORDER BY p.id LIMIT 1),
+			   (SELECT jsonb_agg(to_jsonb(i) ORDER BY i.id) FROM items i WHERE i.order_uid = o.order_uid)
+		FROM orders o WHERE o.order_uid = $1
 	`
 	var order models.Order
+	var delivery, payment, items []byte
 	err := r.db.Pool().QueryRow(ctx, orderQuery, orderUID).Scan(
 		&order.OrderUID, &order.TrackNumber, &order.Entry, &order.Locale,
 		&order.InternalSignature, &order.CustomerID, &order.DeliveryService,
-		&order.ShardKey, &order.SmID, &order.DateCreated, &order.OofShard,
+		&order.ShardKey, &order.SmID, &order.DateCreated, &order.OofShard, &order.Version,
+		&delivery, &payment, &items,
 	)
 	if err != nil {
 		if err == pgx.ErrNoRows {
-			return nil, fmt.Errorf("order not found: %s", orderUID)
+			return nil, fmt.Errorf("%w: %s", models.ErrOrderNotFound, orderUID)
 		}
 		return nil, fmt.Errorf("failed to query order: %w", err)
 	}
 
-	// Query delivery
-	deliveryQuery := `
-		SELECT name, phone, zip, city, address, region, email
-		FROM deliveries WHERE order_uid = $1
-	`
-	err = r.db.Pool().QueryRow(ctx, deliveryQuery, orderUID).Scan(
-		&order.Delivery.Name, &order.Delivery.Phone, &order.Delivery.Zip,
-		&order.Delivery.City, &order.Delivery.Address, &order.Delivery.Region, &order.Delivery.Email,
-	)
-	if err != nil && err != pgx.ErrNoRows {
-		return nil, fmt.Errorf("failed to query delivery: %w", err)
-	}
-
-	// Query payment
-	paymentQuery := `
-		SELECT transaction, request_id, currency, provider, amount,
-			   payment_dt, bank, delivery_cost, goods_total, custom_fee
-		FROM payments WHERE order_uid = $1
-	`
-	err = r.db.Pool().QueryRow(ctx, paymentQuery, orderUID).Scan(
-		&order.Payment.Transaction, &order.Payment.RequestID, &order.Payment.Currency,
-		&order.Payment.Provider, &order.Payment.Amount, &order.Payment.PaymentDt,
-		&order.Payment.Bank, &order.Payment.DeliveryCost, &order.Payment.GoodsTotal, &order.Payment.CustomFee,
-	)
-	if err != nil && err != pgx.ErrNoRows {
-		return nil, fmt.Errorf("failed to query payment: %w", err)
+	if delivery != nil {
+		if err := json.Unmarshal(delivery, &order.Delivery); err != nil {
+			return nil, fmt.Errorf("failed to decode delivery: %w", err)
+		}
 	}
-
-	// Query items
-	itemsQuery := `
-		SELECT chrt_id, track_number, price, rid, name, sale,
-			   size, total_price, nm_id, brand, status
-		FROM items WHERE order_uid = $1
-	`
-	rows, err := r.db.Pool().Query(ctx, itemsQuery, orderUID)
-	if err != nil {
-		return nil, fmt.Errorf("failed to query items: %w", err)
+	if payment != nil {
+		if err := json.Unmarshal(payment, &order.Payment); err != nil {
+			return nil, fmt.Errorf("failed to decode payment: %w", err)
+		}
 	}
-	defer rows.Close()
-
-	var items []models.Item
-	for rows.Next() {
-		var item models.Item
-		err := rows.Scan(
-			&item.ChrtID, &item.TrackNumber, &item.Price, &item.Rid,
-			&item.Name, &item.Sale, &item.Size, &item.TotalPrice,
-			&item.NmID, &item.Brand, &item.Status,
-		)
-		if err != nil {
-			return nil, fmt.Errorf("failed to scan item: %w", err)
+	if items != nil {
+		if err := json.Unmarshal(items, &order.Items); err != nil {
+			return nil, fmt.Errorf("failed to decode items: %w", err)
 		}
-		items = append(items, item)
 	}
-	order.Items = items
 
 	return &order, nil
 }
@@ -0,0 +1,98 @@
+package order
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"time"
+
+	"wb-test/internal/models"
+	"wb-test/pkg/metrics"
+	"wb-test/pkg/tracing"
+	"wb-test/pkg/utils"
+
+	"github.com/jackc/pgx/v5"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// UpdateOrder replaces an order with its delivery, payment and items in one
+// transaction, or inserts it if it does not exist. A non-zero order.Version
+// must match the stored version. On success order.Version holds the new version.
+func (r *orderRepo) UpdateOrder(ctx context.Context, order *models.Order) (err error) {
+	defer metrics.ObserveDBQuery("update_order", time.Now())
+	ctx, span := tracer.Start(ctx, "orderRepo.UpdateOrder", trace.WithAttributes(attribute.String("order.uid", order.OrderUID)))
+	defer func() { tracing.End(span, err) }()
+	defer func() { err = classifyError(err) }()
+
+	hash, err := order.ContentHash()
+	if err != nil {
+		return utils.Permanent(fmt.Errorf("failed to hash order: %w", err))
+	}
+
+	tx, err := r.db.Pool().Begin(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback(ctx)
+
+	// Upsert order, the version check makes a concurrent update lose instead of
+	// silently overwriting
+	orderQuery := `
+		INSERT INTO orders (
+			order_uid, track_number, entry, locale, internal_signature,
+			customer_id, delivery_service, shard_key, sm_id, date_created, oof_shard, content_hash
+		) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12)
+		ON CONFLICT (order_uid) DO UPDATE SET
+			track_number = EXCLUDED.track_number,
+			entry = EXCLUDED.entry,
+			locale = EXCLUDED.locale,
+			internal_signature = EXCLUDED.internal_signature,
+			customer_id = EXCLUDED.customer_id,
+			delivery_service = EXCLUDED.delivery_service,
+			shard_key = EXCLUDED.shard_key,
+			sm_id = EXCLUDED.sm_id,
+			date_created = EXCLUDED.date_created,
+			oof_shard = EXCLUDED.oof_shard,
+			content_hash = EXCLUDED.content_hash,
+			version = orders.version + 1,
+			updated_at = NOW()
+		WHERE $13 = 0 OR orders.version = $13
+		RETURNING version
+	`
+	var version int
+	err = tx.QueryRow(ctx, orderQuery,
+		order.OrderUID, order.TrackNumber, order.Entry, order.Locale,
+		order.InternalSignature, order.CustomerID, order.DeliveryService,
+		order.ShardKey, order.SmID, order.DateCreated, order.OofShard, hash,
+		order.Version,
+	).Scan(&version)
+	if err == pgx.ErrNoRows {
+		return utils.Permanent(fmt.Errorf("%w: %s expected version %d", models.ErrOrderVersionMismatch, order.OrderUID, order.Version))
+	}
+	if err != nil {
+		return fmt.Errorf("failed to upsert order: %w", err)
+	}
+
+	// Replace delivery, payment and items
+	for _, table := range []string{"deliveries", "payments", "items"} {
+		if _, err := tx.Exec(ctx, "DELETE FROM "+table+" WHERE order_uid = $1", order.OrderUID); err != nil {
+			return fmt.Errorf("failed to delete %s: %w", table, err)
+		}
+	}
+	if err := insertOrderDetails(ctx, tx, order); err != nil {
+		return err
+	}
+	if err := appendHistory(ctx, tx, order, version, models.SourceUpdate); err != nil {
+		return err
+	}
+
+	// Commit transaction
+	if err = tx.Commit(ctx); err != nil {
+		return fmt.Errorf("failed to commit transaction: %w", err)
+	}
+
+	order.Version = version
+	slog.Info("Order updated in database", "order_uid", order.OrderUID, "version", version)
+	return nil
+}
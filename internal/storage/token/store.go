@@ -0,0 +1,115 @@
+package token
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"wb-test/internal/models"
+	"wb-test/pkg/cache"
+
+	"github.com/redis/go-redis/v9"
+)
+
+// tokenStore keeps refresh sessions and the access token denylist in Redis.
+// Refresh tokens are stored by hash only, a Redis dump does not leak usable tokens.
+type tokenStore struct {
+	client *cache.RedisClient
+}
+
+func NewTokenStore(client *cache.RedisClient) *tokenStore {
+	return &tokenStore{client: client}
+}
+
+func sessionKey(hash string) string  { return fmt.Sprintf("refresh:%s", hash) }
+func usedKey(hash string) string     { return fmt.Sprintf("refresh-used:%s", hash) }
+func familyKey(family string) string { return fmt.Sprintf("refresh-family-revoked:%s", family) }
+func denyKey(jti string) string      { return fmt.Sprintf("jwt-deny:%s", jti) }
+
+func (s *tokenStore) SaveSession(hash string, session *models.RefreshSession) error {
+	ctx := context.Background()
+
+	data, err := json.Marshal(session)
+	if err != nil {
+		return fmt.Errorf("failed to marshal refresh session: %w", err)
+	}
+
+	if err := s.client.Client().Set(ctx, sessionKey(hash), data, time.Until(session.ExpiresAt)).Err(); err != nil {
+		return fmt.Errorf("failed to save refresh session: %w", err)
+	}
+	return nil
+}
+
+// GetSession returns nil without error when the token is unknown or expired
+func (s *tokenStore) GetSession(hash string) (*models.RefreshSession, error) {
+	ctx := context.Background()
+
+	data, err := s.client.Client().Get(ctx, sessionKey(hash)).Bytes()
+	if err != nil {
+		if err == redis.Nil {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to get refresh session: %w", err)
+	}
+
+	var session models.RefreshSession
+	if err := json.Unmarshal(data, &session); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal refresh session: %w", err)
+	}
+	return &session, nil
+}
+
+// MarkUsed flags a refresh token as rotated, it returns false when the token
+// was already used. The flag outlives the session so reuse is still detected.
+func (s *tokenStore) MarkUsed(hash string, ttl time.Duration) (bool, error) {
+	ctx := context.Background()
+
+	first, err := s.client.Client().SetNX(ctx, usedKey(hash), 1, ttl).Result()
+	if err != nil {
+		return false, fmt.Errorf("failed to mark refresh token used: %w", err)
+	}
+	return first, nil
+}
+
+func (s *tokenStore) RevokeFamily(family string, ttl time.Duration) error {
+	ctx := context.Background()
+
+	if err := s.client.Client().Set(ctx, familyKey(family), 1, ttl).Err(); err != nil {
+		return fmt.Errorf("failed to revoke refresh token family: %w", err)
+	}
+	return nil
+}
+
+func (s *tokenStore) IsFamilyRevoked(family string) (bool, error) {
+	ctx := context.Background()
+
+	n, err := s.client.Client().Exists(ctx, familyKey(family)).Result()
+	if err != nil {
+		return false, fmt.Errorf("failed to check refresh token family: %w", err)
+	}
+	return n > 0, nil
+}
+
+// DenyToken revokes an access token until it would have expired anyway
+func (s *tokenStore) DenyToken(jti string, ttl time.Duration) error {
+	ctx := context.Background()
+
+	if ttl <= 0 {
+		return nil
+	}
+	if err := s.client.Client().Set(ctx, denyKey(jti), 1, ttl).Err(); err != nil {
+		return fmt.Errorf("failed to deny token: %w", err)
+	}
+	return nil
+}
+
+func (s *tokenStore) IsTokenDenied(jti string) (bool, error) {
+	ctx := context.Background()
+
+	n, err := s.client.Client().Exists(ctx, denyKey(jti)).Result()
+	if err != nil {
+		return false, fmt.Errorf("failed to check token denylist: %w", err)
+	}
+	return n > 0, nil
+}
@@ -0,0 +1,140 @@
+package user
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"wb-test/internal/models"
+	"wb-test/pkg/db"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+)
+
+// uniqueViolation is the Postgres error code of a duplicate key
+const uniqueViolation = "23505"
+
+type userRepo struct {
+	db *db.PostgresClient
+}
+
+func NewUserRepo(db *db.PostgresClient) *userRepo {
+	return &userRepo{db: db}
+}
+
+func (r *userRepo) CreateUser(user *models.User) error {
+	ctx := context.Background()
+
+	query := `
+		INSERT INTO users (username, password_hash, roles, scopes)
+		VALUES ($1, $2, $3, $4)
+		RETURNING id, created_at
+	`
+	err := r.db.Pool().QueryRow(ctx, query,
+		user.Username, user.PasswordHash, nonNil(user.Roles), nonNil(user.Scopes),
+	).Scan(&user.ID, &user.CreatedAt)
+	if err != nil {
+		var pgErr *pgconn.PgError
+		if errors.As(err, &pgErr) && pgErr.Code == uniqueViolation {
+			return models.ErrUserExists
+		}
+		return fmt.Errorf("failed to insert user: %w", err)
+	}
+
+	return nil
+}
+
+func (r *userRepo) GetUserByUsername(username string) (*models.User, error) {
+	return r.getUser("username = $1", username)
+}
+
+func (r *userRepo) GetUserByID(userID int) (*models.User, error) {
+	return r.getUser("id = $1", userID)
+}
+
+func (r *userRepo) getUser(where string, arg any) (*models.User, error) {
+	ctx := context.Background()
+
+	query := `
+		SELECT id, username, password_hash, roles, scopes, failed_attempts, locked_until, created_at
+		FROM users
+		WHERE ` + where
+	var user models.User
+	err := r.db.Pool().QueryRow(ctx, query, arg).Scan(
+		&user.ID, &user.Username, &user.PasswordHash, &user.Roles, &user.Scopes,
+		&user.FailedAttempts, &user.LockedUntil, &user.CreatedAt,
+	)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, models.ErrUserNotFound
+		}
+		return nil, fmt.Errorf("failed to get user: %w", err)
+	}
+
+	return &user, nil
+}
+
+// SetUserRoles replaces the roles and scopes of a user
+func (r *userRepo) SetUserRoles(username string, roles, scopes []string) error {
+	ctx := context.Background()
+
+	tag, err := r.db.Pool().Exec(ctx, `
+		UPDATE users SET roles = $2, scopes = $3, updated_at = NOW()
+		WHERE username = $1
+	`, username, nonNil(roles), nonNil(scopes))
+	if err != nil {
+		return fmt.Errorf("failed to update user roles: %w", err)
+	}
+	if tag.RowsAffected() == 0 {
+		return models.ErrUserNotFound
+	}
+
+	return nil
+}
+
+// RecordLoginFailure counts a failed login in a single statement, so
+// concurrent attempts cannot slip past the limit. Reaching maxAttempts locks
+// the user for lockout and starts the count over.
+func (r *userRepo) RecordLoginFailure(userID, maxAttempts int, lockout time.Duration) (locked bool, err error) {
+	ctx := context.Background()
+
+	query := `
+		UPDATE users SET
+			failed_attempts = CASE WHEN failed_attempts + 1 >= $2 THEN 0 ELSE failed_attempts + 1 END,
+			locked_until = CASE WHEN failed_attempts + 1 >= $2 THEN NOW() + $3 * INTERVAL '1 microsecond' ELSE locked_until END,
+			updated_at = NOW()
+		WHERE id = $1
+		RETURNING failed_attempts = 0
+	`
+	err = r.db.Pool().QueryRow(ctx, query, userID, maxAttempts, lockout.Microseconds()).Scan(&locked)
+	if err != nil {
+		return false, fmt.Errorf("failed to record login failure: %w", err)
+	}
+
+	return locked, nil
+}
+
+// RecordLoginSuccess clears the failure count and any expired lock
+func (r *userRepo) RecordLoginSuccess(userID int) error {
+	ctx := context.Background()
+
+	_, err := r.db.Pool().Exec(ctx, `
+		UPDATE users SET failed_attempts = 0, locked_until = NULL, updated_at = NOW()
+		WHERE id = $1 AND (failed_attempts <> 0 OR locked_until IS NOT NULL)
+	`, userID)
+	if err != nil {
+		return fmt.Errorf("failed to record login success: %w", err)
+	}
+
+	return nil
+}
+
+// nonNil keeps NOT NULL array columns from receiving NULL
+func nonNil(values []string) []string {
+	if values == nil {
+		return []string{}
+	}
+	return values
+}
@@ -0,0 +1,163 @@
+package validation
+
+import (
+	"fmt"
+	"net/mail"
+	"regexp"
+	"strings"
+	"unicode/utf8"
+
+	"wb-test/internal/models"
+	httputils "wb-test/pkg/utils/http-utils"
+)
+
+var (
+	phoneRe    = regexp.MustCompile(`^\+?[0-9]{7,15}$`)
+	currencyRe = regexp.MustCompile(`^[A-Z]{3}$`)
+	localeRe   = regexp.MustCompile(`^[a-z]{2}(-[A-Z]{2})?$`)
+)
+
+// Errors is a list of field errors, it is returned as an error by ValidateOrder
+type Errors []httputils.ErrorDetail
+
+func (e Errors) Error() string {
+	msgs := make([]string, 0, len(e))
+	for _, d := range e {
+		msgs = append(msgs, d.Field+": "+d.Message)
+	}
+	return "invalid order: " + strings.Join(msgs, "; ")
+}
+
+// Details returns the field errors for an HTTP error response
+func (e Errors) Details() []httputils.ErrorDetail {
+	return e
+}
+
+// validator collects field errors
+type validator struct {
+	errs Errors
+}
+
+func (v *validator) add(field, format string, args ...any) {
+	v.errs = append(v.errs, httputils.ErrorDetail{Field: field, Message: fmt.Sprintf(format, args...)})
+}
+
+// required checks a non-empty string that fits into a column of maxLen characters
+func (v *validator) required(field, value string, maxLen int) {
+	if strings.TrimSpace(value) == "" {
+		v.add(field, "is required")
+		return
+	}
+	v.maxLen(field, value, maxLen)
+}
+
+func (v *validator) maxLen(field, value string, maxLen int) {
+	if maxLen > 0 && utf8.RuneCountInString(value) > maxLen {
+		v.add(field, "must be at most %d characters", maxLen)
+	}
+}
+
+func (v *validator) nonNegative(field string, value int) {
+	if value < 0 {
+		v.add(field, "must not be negative")
+	}
+}
+
+func (v *validator) match(field, value string, re *regexp.Regexp, what string) {
+	if value != "" && !re.MatchString(value) {
+		v.add(field, "must be a valid %s", what)
+	}
+}
+
+// ValidateOrder checks required fields, formats and cross-field invariants of
+// an order. It returns Errors if anything is wrong.
+func ValidateOrder(order *models.Order) error {
+	v := &validator{}
+
+	// Order
+	v.required("order_uid", order.OrderUID, 255)
+	v.required("track_number", order.TrackNumber, 255)
+	v.required("entry", order.Entry, 50)
+	v.required("locale", order.Locale, 10)
+	v.match("locale", order.Locale, localeRe, "locale (e.g. en or en-US)")
+	v.required("customer_id", order.CustomerID, 255)
+	v.required("delivery_service", order.DeliveryService, 100)
+	v.required("shardkey", order.ShardKey, 10)
+	v.required("oof_shard", order.OofShard, 10)
+	v.nonNegative("sm_id", order.SmID)
+	if order.DateCreated.IsZero() {
+		v.add("date_created", "is required")
+	}
+
+	// Delivery
+	d := order.Delivery
+	v.required("delivery.name", d.Name, 255)
+	v.required("delivery.phone", d.Phone, 50)
+	v.match("delivery.phone", d.Phone, phoneRe, "phone number")
+	v.required("delivery.zip", d.Zip, 20)
+	v.required("delivery.city", d.City, 255)
+	v.required("delivery.address", d.Address, 0)
+	v.required("delivery.region", d.Region, 255)
+	v.required("delivery.email", d.Email, 255)
+	if d.Email != "" {
+		if addr, err := mail.ParseAddress(d.Email); err != nil || addr.Address != d.Email {
+			v.add("delivery.email", "must be a valid email address")
+		}
+	}
+
+	// Payment
+	p := order.Payment
+	v.required("payment.transaction", p.Transaction, 255)
+	v.maxLen("payment.request_id", p.RequestID, 255)
+	v.required("payment.currency", p.Currency, 10)
+	v.match("payment.currency", p.Currency, currencyRe, "ISO 4217 currency code")
+	v.required("payment.provider", p.Provider, 100)
+	v.required("payment.bank", p.Bank, 100)
+	v.nonNegative("payment.amount", p.Amount)
+	v.nonNegative("payment.delivery_cost", p.DeliveryCost)
+	v.nonNegative("payment.goods_total", p.GoodsTotal)
+	v.nonNegative("payment.custom_fee", p.CustomFee)
+	if p.PaymentDt <= 0 {
+		v.add("payment.payment_dt", "is required")
+	}
+	if total := p.GoodsTotal + p.DeliveryCost + p.CustomFee; p.Amount != total {
+		v.add("payment.amount", "must equal goods_total + delivery_cost + custom_fee (%d)", total)
+	}
+
+	// Items
+	if len(order.Items) == 0 {
+		v.add("items", "must contain at least one item")
+	}
+	goodsTotal := 0
+	for i, item := range order.Items {
+		field := fmt.Sprintf("items[%d]", i)
+		if item.ChrtID <= 0 {
+			v.add(field+".chrt_id", "is required")
+		}
+		v.required(field+".track_number", item.TrackNumber, 255)
+		if item.TrackNumber != "" && item.TrackNumber != order.TrackNumber {
+			v.add(field+".track_number", "must match the order track_number")
+		}
+		v.required(field+".rid", item.Rid, 255)
+		v.required(field+".name", item.Name, 500)
+		v.required(field+".size", item.Size, 50)
+		v.required(field+".brand", item.Brand, 255)
+		v.nonNegative(field+".price", item.Price)
+		v.nonNegative(field+".total_price", item.TotalPrice)
+		if item.Sale < 0 || item.Sale > 100 {
+			v.add(field+".sale", "must be between 0 and 100")
+		}
+		if item.NmID <= 0 {
+			v.add(field+".nm_id", "is required")
+		}
+		goodsTotal += item.TotalPrice
+	}
+	if len(order.Items) > 0 && p.GoodsTotal != goodsTotal {
+		v.add("payment.goods_total", "must equal the sum of items total_price (%d)", goodsTotal)
+	}
+
+	if len(v.errs) > 0 {
+		return v.errs
+	}
+	return nil
+}
@@ -0,0 +1,29 @@
+-- +goose Up
+-- +goose StatementBegin
+CREATE INDEX IF NOT EXISTS idx_orders_date_created_order_uid ON orders (date_created DESC, order_uid DESC);
+CREATE INDEX IF NOT EXISTS idx_orders_customer_id_date_created ON orders (customer_id, date_created DESC, order_uid DESC);
+CREATE INDEX IF NOT EXISTS idx_orders_track_number ON orders (track_number);
+CREATE INDEX IF NOT EXISTS idx_orders_delivery_service_date_created ON orders (delivery_service, date_created DESC, order_uid DESC);
+CREATE INDEX IF NOT EXISTS idx_deliveries_order_uid ON deliveries (order_uid);
+CREATE INDEX IF NOT EXISTS idx_payments_order_uid ON payments (order_uid);
+CREATE INDEX IF NOT EXISTS idx_payments_provider ON payments (provider, order_uid);
+CREATE INDEX IF NOT EXISTS idx_payments_currency ON payments (currency, order_uid);
+CREATE INDEX IF NOT EXISTS idx_items_order_uid ON items (order_uid);
+CREATE INDEX IF NOT EXISTS idx_items_brand ON items (brand, order_uid);
+CREATE INDEX IF NOT EXISTS idx_items_nm_id ON items (nm_id, order_uid);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP INDEX IF EXISTS idx_items_nm_id;
+DROP INDEX IF EXISTS idx_items_brand;
+DROP INDEX IF EXISTS idx_items_order_uid;
+DROP INDEX IF EXISTS idx_payments_currency;
+DROP INDEX IF EXISTS idx_payments_provider;
+DROP INDEX IF EXISTS idx_payments_order_uid;
+DROP INDEX IF EXISTS idx_deliveries_order_uid;
+DROP INDEX IF EXISTS idx_orders_delivery_service_date_created;
+DROP INDEX IF EXISTS idx_orders_track_number;
+DROP INDEX IF EXISTS idx_orders_customer_id_date_created;
+DROP INDEX IF EXISTS idx_orders_date_created_order_uid;
+-- +goose StatementEnd
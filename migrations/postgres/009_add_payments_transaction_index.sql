@@ -0,0 +1,10 @@
+-- +goose Up
+-- +goose StatementBegin
+-- orders.track_number and orders.customer_id are indexed by 008
+CREATE INDEX IF NOT EXISTS idx_payments_transaction ON payments (transaction, order_uid);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP INDEX IF EXISTS idx_payments_transaction;
+-- +goose StatementEnd
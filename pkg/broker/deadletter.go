@@ -0,0 +1,226 @@
+package broker
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/nats-io/nats.go"
+	"github.com/nats-io/nats.go/jetstream"
+)
+
+// Headers attached to dead-lettered messages
+const (
+	HeaderDLQError           = "Dlq-Error"
+	HeaderDLQAttempts        = "Dlq-Attempts"
+	HeaderDLQOriginalSubject = "Dlq-Original-Subject"
+	HeaderDLQFailedAt        = "Dlq-Failed-At"
+	HeaderDLQReason          = "Dlq-Reason"
+)
+
+// Dead-letter reasons, conflicts are published to a separate subject
+const (
+	ReasonFailed   = "failed"
+	ReasonConflict = "conflict"
+)
+
+var ErrDeadLetterNotFound = errors.New("dead letter not found")
+
+// DeadLetter is a message that could not be processed
+type DeadLetter struct {
+	Sequence        uint64    `json:"sequence"`
+	Subject         string    `json:"subject"`
+	Reason          string    `json:"reason"`
+	OriginalSubject string    `json:"original_subject"`
+	Error           string    `json:"error"`
+	Attempts        int       `json:"attempts"`
+	FailedAt        time.Time `json:"failed_at"`
+	Data            []byte    `json:"data" swaggertype:"string" format:"base64"`
+}
+
+// DeadLetterQueue stores failed messages in a JetStream stream, whether orders
+// themselves go over JetStream or core NATS. Provision must succeed first, a
+// publish only succeeds once the stream acknowledged the message.
+type DeadLetterQueue struct {
+	client  *NATSClient
+	subject string
+	stream  string
+	maxAge  time.Duration
+}
+
+// DeadLetterQueue returns a dead-letter queue publishing to subject and stored
+// in stream
+func (n *NATSClient) DeadLetterQueue(subject, stream string, maxAge time.Duration) *DeadLetterQueue {
+	return &DeadLetterQueue{
+		client:  n,
+		subject: subject,
+		stream:  stream,
+		maxAge:  maxAge,
+	}
+}
+
+// ConflictSubject is where re-submissions conflicting with a stored order go
+func (q *DeadLetterQueue) ConflictSubject() string {
+	return q.subject + "." + ReasonConflict
+}
+
+// Provision creates the stream that stores dead letters
+func (q *DeadLetterQueue) Provision(ctx context.Context) error {
+	js, err := q.client.streams()
+	if err != nil {
+		return err
+	}
+
+	_, err = ensureStream(ctx, js, StreamConfig{
+		Name:     q.stream,
+		Subjects: []string{q.subject, q.ConflictSubject()},
+		MaxAge:   q.maxAge,
+	})
+	return err
+}
+
+// Publish sends a failed payload to the dead-letter subject
+func (q *DeadLetterQueue) Publish(originalSubject string, data []byte, attempts int, cause error) error {
+	return q.publish(q.subject, ReasonFailed, originalSubject, data, attempts, cause)
+}
+
+// PublishConflict sends a payload that conflicts with a stored order to the
+// conflict subject
+func (q *DeadLetterQueue) PublishConflict(originalSubject string, data []byte, attempts int, cause error) error {
+	return q.publish(q.ConflictSubject(), ReasonConflict, originalSubject, data, attempts, cause)
+}
+
+func (q *DeadLetterQueue) publish(subject, reason, originalSubject string, data []byte, attempts int, cause error) error {
+	msg := nats.NewMsg(subject)
+	msg.Data = data
+	msg.Header.Set(HeaderDLQReason, reason)
+	msg.Header.Set(HeaderDLQError, cause.Error())
+	msg.Header.Set(HeaderDLQAttempts, strconv.Itoa(attempts))
+	msg.Header.Set(HeaderDLQOriginalSubject, originalSubject)
+	msg.Header.Set(HeaderDLQFailedAt, time.Now().UTC().Format(time.RFC3339))
+
+	js, err := q.client.streams()
+	if err != nil {
+		return err
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), publishTimeout)
+	defer cancel()
+
+	// Without a stream capturing the subject the publish is not acknowledged
+	if _, err := js.PublishMsg(ctx, msg); err != nil {
+		return fmt.Errorf("failed to publish dead letter: %w", err)
+	}
+
+	return nil
+}
+
+// List returns up to limit oldest dead letters
+func (q *DeadLetterQueue) List(ctx context.Context, limit int) ([]DeadLetter, error) {
+	stream, err := q.streamHandle(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	info, err := stream.Info(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get dead-letter stream info: %w", err)
+	}
+
+	letters := make([]DeadLetter, 0)
+	if info.State.Msgs == 0 {
+		return letters, nil
+	}
+
+	// Replayed letters leave gaps in the sequence, asking for the next message
+	// on any subject skips them in a single round trip
+	for seq := info.State.FirstSeq; len(letters) < limit; {
+		msg, err := stream.GetMsg(ctx, seq, jetstream.WithGetMsgSubject(">"))
+		if err != nil {
+			if errors.Is(err, jetstream.ErrMsgNotFound) {
+				break
+			}
+			return nil, fmt.Errorf("failed to get dead letter after %d: %w", seq, err)
+		}
+		letters = append(letters, toDeadLetter(msg))
+		seq = msg.Sequence + 1
+	}
+
+	return letters, nil
+}
+
+// Replay republishes a dead letter to its original subject (or fallbackSubject
+// if it has none) and removes it from the queue
+func (q *DeadLetterQueue) Replay(ctx context.Context, seq uint64, fallbackSubject string) error {
+	stream, err := q.streamHandle(ctx)
+	if err != nil {
+		return err
+	}
+
+	raw, err := stream.GetMsg(ctx, seq)
+	if err != nil {
+		if errors.Is(err, jetstream.ErrMsgNotFound) {
+			return fmt.Errorf("%w: %d", ErrDeadLetterNotFound, seq)
+		}
+		return fmt.Errorf("failed to get dead letter %d: %w", seq, err)
+	}
+
+	subject := raw.Header.Get(HeaderDLQOriginalSubject)
+	if subject == "" {
+		subject = fallbackSubject
+	}
+
+	msg := nats.NewMsg(subject)
+	msg.Data = raw.Data
+	// The replay continues the trace of the request that triggered it
+	InjectTrace(ctx, msg.Header)
+	if err := q.client.publishMsg(msg); err != nil {
+		return fmt.Errorf("failed to replay dead letter %d: %w", seq, err)
+	}
+
+	if err := stream.DeleteMsg(ctx, seq); err != nil {
+		return fmt.Errorf("failed to delete replayed dead letter %d: %w", seq, err)
+	}
+
+	return nil
+}
+
+func (q *DeadLetterQueue) streamHandle(ctx context.Context) (jetstream.Stream, error) {
+	js, err := q.client.streams()
+	if err != nil {
+		return nil, err
+	}
+
+	stream, err := js.Stream(ctx, q.stream)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get dead-letter stream %s: %w", q.stream, err)
+	}
+
+	return stream, nil
+}
+
+func toDeadLetter(msg *jetstream.RawStreamMsg) DeadLetter {
+	attempts, _ := strconv.Atoi(msg.Header.Get(HeaderDLQAttempts))
+	failedAt, err := time.Parse(time.RFC3339, msg.Header.Get(HeaderDLQFailedAt))
+	if err != nil {
+		failedAt = msg.Time
+	}
+
+	reason := msg.Header.Get(HeaderDLQReason)
+	if reason == "" {
+		reason = ReasonFailed
+	}
+
+	return DeadLetter{
+		Sequence:        msg.Sequence,
+		Subject:         msg.Subject,
+		Reason:          reason,
+		OriginalSubject: msg.Header.Get(HeaderDLQOriginalSubject),
+		Error:           msg.Header.Get(HeaderDLQError),
+		Attempts:        attempts,
+		FailedAt:        failedAt,
+		Data:            msg.Data,
+	}
+}
@@ -0,0 +1,92 @@
+package broker
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+)
+
+func TestDeadLetterQueueListAndReplay(t *testing.T) {
+	client := newTestClient(t)
+	ctx := context.Background()
+
+	prefix := fmt.Sprintf("test.%d", time.Now().UnixNano())
+	testStream(t, client, prefix+".orders")
+	q := client.DeadLetterQueue(prefix+".dlq", fmt.Sprintf("TEST_DLQ_%d", time.Now().UnixNano()), time.Hour)
+	if err := q.Provision(ctx); err != nil {
+		t.Fatalf("failed to provision dead-letter stream: %v", err)
+	}
+	t.Cleanup(func() {
+		js, _ := client.JetStream()
+		js.DeleteStream(context.Background(), q.stream)
+	})
+
+	for i := 0; i < 3; i++ {
+		if err := q.Publish(prefix+".orders", []byte(fmt.Sprintf(`{"n":%d}`, i)), 3, errors.New("boom")); err != nil {
+			t.Fatalf("failed to publish dead letter: %v", err)
+		}
+	}
+	if err := q.PublishConflict(prefix+".orders", []byte(`{"n":3}`), 1, errors.New("conflict")); err != nil {
+		t.Fatalf("failed to publish conflict: %v", err)
+	}
+
+	letters, err := q.List(ctx, 10)
+	if err != nil {
+		t.Fatalf("failed to list dead letters: %v", err)
+	}
+	if len(letters) != 4 || letters[0].Attempts != 3 || letters[3].Reason != ReasonConflict {
+		t.Fatalf("unexpected dead letters %+v", letters)
+	}
+
+	// Replaying removes the letter, listing skips the gap it leaves
+	if err := q.Replay(ctx, letters[1].Sequence, ""); err != nil {
+		t.Fatalf("failed to replay dead letter: %v", err)
+	}
+	letters, err = q.List(ctx, 10)
+	if err != nil {
+		t.Fatalf("failed to list dead letters: %v", err)
+	}
+	if len(letters) != 3 || string(letters[1].Data) != `{"n":2}` {
+		t.Errorf("unexpected dead letters after replay %+v", letters)
+	}
+
+	if err := q.Replay(ctx, letters[0].Sequence+1000, ""); !errors.Is(err, ErrDeadLetterNotFound) {
+		t.Errorf("expected not found, got %v", err)
+	}
+}
+
+func TestDeadLetterQueueStoresCoreNATSFailures(t *testing.T) {
+	client := newTestClient(t)
+	// Orders over core NATS, the dead letters still go to a stream
+	client.js = nil
+	ctx := context.Background()
+
+	prefix := fmt.Sprintf("test.%d", time.Now().UnixNano())
+	q := client.DeadLetterQueue(prefix+".dlq", fmt.Sprintf("TEST_DLQ_%d", time.Now().UnixNano()), time.Hour)
+
+	// Nothing stores the subject yet, so the publish must not succeed
+	if err := q.Publish(prefix+".orders", []byte(`{}`), 1, errors.New("boom")); err == nil {
+		t.Fatal("expected publishing without a dead-letter stream to fail")
+	}
+
+	if err := q.Provision(ctx); err != nil {
+		t.Fatalf("failed to provision dead-letter stream: %v", err)
+	}
+	t.Cleanup(func() {
+		js, _ := client.streams()
+		js.DeleteStream(context.Background(), q.stream)
+	})
+
+	if err := q.Publish(prefix+".orders", []byte(`{}`), 1, errors.New("boom")); err != nil {
+		t.Fatalf("failed to publish dead letter: %v", err)
+	}
+	letters, err := q.List(ctx, 10)
+	if err != nil {
+		t.Fatalf("failed to list dead letters: %v", err)
+	}
+	if len(letters) != 1 {
+		t.Errorf("expected one dead letter, got %+v", letters)
+	}
+}
@@ -0,0 +1,121 @@
+package broker
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/nats-io/nats.go/jetstream"
+)
+
+// StreamConfig describes the JetStream stream orders are stored in
+type StreamConfig struct {
+	Name     string
+	Subjects []string
+	MaxAge   time.Duration
+}
+
+// PullConsumerConfig describes a durable pull consumer on a stream
+type PullConsumerConfig struct {
+	Durable        string
+	FilterSubjects []string
+	MaxDeliver     int
+	AckWait        time.Duration
+}
+
+// EnableJetStream switches the client to JetStream, publishes then wait for a
+// stream acknowledgement instead of being fire-and-forget
+func (n *NATSClient) EnableJetStream() error {
+	js, err := jetstream.New(n.conn)
+	if err != nil {
+		return fmt.Errorf("failed to create JetStream context: %w", err)
+	}
+
+	n.js = js
+	return nil
+}
+
+// JetStream returns the JetStream context, EnableJetStream must be called first
+func (n *NATSClient) JetStream() (jetstream.JetStream, error) {
+	if n.js == nil {
+		return nil, errors.New("JetStream is not enabled")
+	}
+	return n.js, nil
+}
+
+// streams returns a JetStream context even when orders go over core NATS,
+// the dead-letter queue is stored in a stream either way
+func (n *NATSClient) streams() (jetstream.JetStream, error) {
+	if n.js != nil {
+		return n.js, nil
+	}
+
+	js, err := jetstream.New(n.conn)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create JetStream context: %w", err)
+	}
+	return js, nil
+}
+
+// EnsureStream creates the stream or updates it to match cfg
+func (n *NATSClient) EnsureStream(ctx context.Context, cfg StreamConfig) (jetstream.Stream, error) {
+	js, err := n.JetStream()
+	if err != nil {
+		return nil, err
+	}
+
+	return ensureStream(ctx, js, cfg)
+}
+
+func ensureStream(ctx context.Context, js jetstream.JetStream, cfg StreamConfig) (jetstream.Stream, error) {
+	stream, err := js.CreateOrUpdateStream(ctx, jetstream.StreamConfig{
+		Name:      cfg.Name,
+		Subjects:  cfg.Subjects,
+		MaxAge:    cfg.MaxAge,
+		Retention: jetstream.LimitsPolicy,
+		Storage:   jetstream.FileStorage,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to provision stream %s: %w", cfg.Name, err)
+	}
+
+	return stream, nil
+}
+
+// EnsurePullConsumer creates the durable pull consumer or updates it to match cfg
+func (n *NATSClient) EnsurePullConsumer(ctx context.Context, stream string, cfg PullConsumerConfig) (jetstream.Consumer, error) {
+	js, err := n.JetStream()
+	if err != nil {
+		return nil, err
+	}
+
+	consumer, err := js.CreateOrUpdateConsumer(ctx, stream, jetstream.ConsumerConfig{
+		Durable:        cfg.Durable,
+		FilterSubjects: cfg.FilterSubjects,
+		AckPolicy:      jetstream.AckExplicitPolicy,
+		AckWait:        cfg.AckWait,
+		MaxDeliver:     cfg.MaxDeliver,
+		DeliverPolicy:  jetstream.DeliverAllPolicy,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to provision consumer %s: %w", cfg.Durable, err)
+	}
+
+	return consumer, nil
+}
+
+// PullConsumer looks up an existing durable pull consumer without provisioning it
+func (n *NATSClient) PullConsumer(ctx context.Context, stream, durable string) (jetstream.Consumer, error) {
+	js, err := n.JetStream()
+	if err != nil {
+		return nil, err
+	}
+
+	consumer, err := js.Consumer(ctx, stream, durable)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get consumer %s on stream %s: %w", durable, stream, err)
+	}
+
+	return consumer, nil
+}
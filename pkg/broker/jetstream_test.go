@@ -0,0 +1,106 @@
+package broker
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/nats-io/nats.go/jetstream"
+)
+
+// newTestClient connects to the JetStream enabled server in NATS_URL, tests
+// are skipped without it:
+//
+//	NATS_URL=nats://localhost:4222 go test ./pkg/broker
+func newTestClient(t *testing.T) *NATSClient {
+	t.Helper()
+
+	url := os.Getenv("NATS_URL")
+	if url == "" {
+		t.Skip("NATS_URL is not set")
+	}
+
+	client, err := NewNATS(context.Background(), url)
+	if err != nil {
+		t.Fatalf("failed to connect to NATS: %v", err)
+	}
+	t.Cleanup(client.Close)
+	if err := client.EnableJetStream(); err != nil {
+		t.Fatalf("failed to enable JetStream: %v", err)
+	}
+	return client
+}
+
+// testStream provisions a stream unique to the test and deletes it afterwards
+func testStream(t *testing.T, client *NATSClient, subjects ...string) StreamConfig {
+	t.Helper()
+
+	cfg := StreamConfig{Name: fmt.Sprintf("TEST_%d", time.Now().UnixNano()), Subjects: subjects, MaxAge: time.Hour}
+	if _, err := client.EnsureStream(context.Background(), cfg); err != nil {
+		t.Fatalf("failed to provision stream: %v", err)
+	}
+	t.Cleanup(func() {
+		js, _ := client.JetStream()
+		js.DeleteStream(context.Background(), cfg.Name)
+	})
+	return cfg
+}
+
+func TestEnsureStreamAndConsumerAreIdempotent(t *testing.T) {
+	client := newTestClient(t)
+	ctx := context.Background()
+	subject := fmt.Sprintf("test.%d.orders", time.Now().UnixNano())
+	cfg := testStream(t, client, subject)
+
+	// Provisioning again with a changed config updates the stream
+	cfg.MaxAge = 2 * time.Hour
+	stream, err := client.EnsureStream(ctx, cfg)
+	if err != nil {
+		t.Fatalf("failed to update stream: %v", err)
+	}
+	if got := stream.CachedInfo().Config.MaxAge; got != 2*time.Hour {
+		t.Errorf("expected max age to be updated, got %s", got)
+	}
+
+	consumerCfg := PullConsumerConfig{Durable: "test", FilterSubjects: []string{subject}, MaxDeliver: 3, AckWait: time.Second}
+	if _, err := client.EnsurePullConsumer(ctx, cfg.Name, consumerCfg); err != nil {
+		t.Fatalf("failed to provision consumer: %v", err)
+	}
+	consumer, err := client.EnsurePullConsumer(ctx, cfg.Name, consumerCfg)
+	if err != nil {
+		t.Fatalf("failed to provision consumer again: %v", err)
+	}
+	if info := consumer.CachedInfo(); info.Config.MaxDeliver != 3 || info.Config.AckPolicy != jetstream.AckExplicitPolicy {
+		t.Errorf("unexpected consumer config %+v", info.Config)
+	}
+
+	if _, err := client.PullConsumer(ctx, cfg.Name, "test"); err != nil {
+		t.Errorf("failed to look up provisioned consumer: %v", err)
+	}
+}
+
+func TestPublishOrderIsStoredInStream(t *testing.T) {
+	client := newTestClient(t)
+	ctx := context.Background()
+	subject := fmt.Sprintf("test.%d.orders", time.Now().UnixNano())
+	cfg := testStream(t, client, subject)
+
+	if err := client.PublishOrder(ctx, subject, map[string]string{"order_uid": "a"}); err != nil {
+		t.Fatalf("failed to publish order: %v", err)
+	}
+
+	js, _ := client.JetStream()
+	stream, err := js.Stream(ctx, cfg.Name)
+	if err != nil {
+		t.Fatalf("failed to get stream: %v", err)
+	}
+	msg, err := stream.GetLastMsgForSubject(ctx, subject)
+	if err != nil {
+		t.Fatalf("published order not stored: %v", err)
+	}
+	if string(msg.Data) != `{"order_uid":"a"}` {
+		t.Errorf("unexpected payload %s", msg.Data)
+	}
+}
@@ -4,13 +4,21 @@ import (
 	"context"
 	"encoding/json"
 	"fmt"
+	"log/slog"
 	"time"
 
+	"wb-test/pkg/tracing"
+
 	"github.com/nats-io/nats.go"
+	"github.com/nats-io/nats.go/jetstream"
+	"go.opentelemetry.io/otel/trace"
 )
 
+const publishTimeout = 5 * time.Second
+
 type NATSClient struct {
 	conn *nats.Conn
+	js   jetstream.JetStream
 }
 
 func NewNATS(ctx context.Context, url string) (*NATSClient, error) {
@@ -42,14 +50,36 @@ func (n *NATSClient) Close() {
 	}
 }
 
-// PublishOrder publishes an order to the specified subject
-func (n *NATSClient) PublishOrder(subject string, order interface{}) error {
+// PublishOrder publishes an order to the specified subject, carrying the
+// trace context of ctx in the message headers
+func (n *NATSClient) PublishOrder(ctx context.Context, subject string, order interface{}) (err error) {
+	ctx, span := tracer.Start(ctx, subject+" publish",
+		trace.WithSpanKind(trace.SpanKindProducer),
+		trace.WithAttributes(messagingAttributes(subject)...),
+	)
+	defer func() { tracing.End(span, err) }()
+
 	data, err := json.Marshal(order)
 	if err != nil {
 		return fmt.Errorf("failed to marshal order: %w", err)
 	}
 
-	err = n.conn.Publish(subject, data)
+	msg := nats.NewMsg(subject)
+	msg.Data = data
+	InjectTrace(ctx, msg.Header)
+
+	if n.js != nil {
+		ctx, cancel := context.WithTimeout(ctx, publishTimeout)
+		defer cancel()
+
+		// Wait for the stream to persist the message
+		if _, err := n.js.PublishMsg(ctx, msg); err != nil {
+			return fmt.Errorf("failed to publish order to stream: %w", err)
+		}
+		return nil
+	}
+
+	err = n.conn.PublishMsg(msg)
 	if err != nil {
 		return fmt.Errorf("failed to publish order: %w", err)
 	}
@@ -57,12 +87,26 @@ func (n *NATSClient) PublishOrder(subject string, order interface{}) error {
 	return nil
 }
 
-// SubscribeToOrders subscribes to orders on the specified subject
-func (n *NATSClient) SubscribeToOrders(subject string, handler func([]byte) error) (*nats.Subscription, error) {
+// publishMsg publishes a raw message, through JetStream when it is enabled
+func (n *NATSClient) publishMsg(msg *nats.Msg) error {
+	if n.js != nil {
+		ctx, cancel := context.WithTimeout(context.Background(), publishTimeout)
+		defer cancel()
+
+		_, err := n.js.PublishMsg(ctx, msg)
+		return err
+	}
+
+	return n.conn.PublishMsg(msg)
+}
+
+// SubscribeToOrders subscribes to orders on the specified subject, the
+// handler context carries the trace context of the message
+func (n *NATSClient) SubscribeToOrders(subject string, handler func(context.Context, []byte) error) (*nats.Subscription, error) {
 	sub, err := n.conn.Subscribe(subject, func(msg *nats.Msg) {
-		if err := handler(msg.Data); err != nil {
+		if err := handler(ExtractTrace(context.Background(), msg.Header), msg.Data); err != nil {
 			// Log error but don't ack the message to allow retry
-			fmt.Printf("Error processing message: %v\n", err)
+			slog.Error("Error processing message", "error", err, "subject", msg.Subject)
 			return
 		}
 		// Acknowledge the message
@@ -76,11 +120,11 @@ func (n *NATSClient) SubscribeToOrders(subject string, handler func([]byte) erro
 }
 
 // SubscribeToOrdersWithQueue subscribes to orders with queue group for load balancing
-func (n *NATSClient) SubscribeToOrdersWithQueue(subject, queueGroup string, handler func([]byte) error) (*nats.Subscription, error) {
+func (n *NATSClient) SubscribeToOrdersWithQueue(subject, queueGroup string, handler func(context.Context, []byte) error) (*nats.Subscription, error) {
 	sub, err := n.conn.QueueSubscribe(subject, queueGroup, func(msg *nats.Msg) {
-		if err := handler(msg.Data); err != nil {
+		if err := handler(ExtractTrace(context.Background(), msg.Header), msg.Data); err != nil {
 			// Log error but don't ack the message to allow retry
-			fmt.Printf("Error processing message: %v\n", err)
+			slog.Error("Error processing message", "error", err, "subject", msg.Subject)
 			return
 		}
 		// Acknowledge the message
@@ -4,6 +4,7 @@ import (
 	"context"
 	"fmt"
 
+	"github.com/redis/go-redis/extra/redisotel/v9"
 	"github.com/redis/go-redis/v9"
 )
 
@@ -24,6 +25,12 @@ func NewRedis(ctx context.Context, addr, password string, db int) (*RedisClient,
 		return nil, fmt.Errorf("failed to ping Redis: %w", err)
 	}
 
+	// Trace commands as children of the caller's span
+	if err := redisotel.InstrumentTracing(client); err != nil {
+		client.Close()
+		return nil, fmt.Errorf("failed to instrument Redis tracing: %w", err)
+	}
+
 	return &RedisClient{client: client}, nil
 }
 
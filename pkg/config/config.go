@@ -1,6 +1,9 @@
 package config
 
 import (
+	"fmt"
+	"time"
+
 	"github.com/ilyakaznacheev/cleanenv"
 )
 
@@ -8,12 +11,20 @@ type Config struct {
 	Server   ServerConfig
 	Database DatabaseConfig
 	Redis    RedisConfig
+	LRU      LRUConfig
 	NATS     NATSConfig
+	Consumer ConsumerConfig
+	Retry    RetryConfig
 	Logger   Logger
+	Warmup   WarmupConfig
+	Auth     AuthConfig
+	Tracing  TracingConfig
 }
 
 type ServerConfig struct {
 	Port string `env:"SERVER_PORT" env-default:"8080"`
+	// MetricsPort serves /metrics apart from the API, without auth
+	MetricsPort string `env:"METRICS_PORT" env-default:"9090"`
 }
 
 type Logger struct {
@@ -30,8 +41,95 @@ type RedisConfig struct {
 	DB       int    `env:"REDIS_DB" env-default:"0"`
 }
 
+// LRUConfig controls the in-process order cache in front of Redis. Updates
+// only evict the instance that handled them, so with several instances the
+// others may serve a stale order for up to TTL. Off unless opted in.
+type LRUConfig struct {
+	Enabled bool          `env:"LRU_CACHE_ENABLED" env-default:"false"`
+	Size    int           `env:"LRU_CACHE_SIZE" env-default:"10000"`
+	TTL     time.Duration `env:"LRU_CACHE_TTL" env-default:"5m"`
+}
+
 type NATSConfig struct {
-	URL string `env:"NATS_URL" env-default:"nats://localhost:4222"`
+	URL        string `env:"NATS_URL" env-default:"nats://localhost:4222"`
+	JetStream  JetStreamConfig
+	DeadLetter DeadLetterConfig
+}
+
+// JetStreamConfig controls the durable stream and pull consumer for orders
+type JetStreamConfig struct {
+	Enabled       bool          `env:"NATS_JS_ENABLED" env-default:"false"`
+	AutoProvision bool          `env:"NATS_JS_AUTO_PROVISION" env-default:"true"`
+	Stream        string        `env:"NATS_JS_STREAM" env-default:"ORDERS"`
+	Subjects      []string      `env:"NATS_JS_SUBJECTS" env-default:"orders.>"`
+	StreamMaxAge  time.Duration `env:"NATS_JS_STREAM_MAX_AGE" env-default:"168h"`
+	Durable       string        `env:"NATS_JS_DURABLE" env-default:"order-processors"`
+	MaxDeliver    int           `env:"NATS_JS_MAX_DELIVER" env-default:"5"`
+	AckWait       time.Duration `env:"NATS_JS_ACK_WAIT" env-default:"30s"`
+	NakDelay      time.Duration `env:"NATS_JS_NAK_DELAY" env-default:"5s"`
+	FetchBatch    int           `env:"NATS_JS_FETCH_BATCH" env-default:"50"`
+	FetchWait     time.Duration `env:"NATS_JS_FETCH_WAIT" env-default:"1s"`
+}
+
+// ConsumerConfig controls how incoming orders are written to the database.
+// BatchSize above 1 switches the consumer to batch mode.
+type ConsumerConfig struct {
+	BatchSize   int           `env:"CONSUMER_BATCH_SIZE" env-default:"1"`
+	BatchWindow time.Duration `env:"CONSUMER_BATCH_WINDOW" env-default:"500ms"`
+}
+
+// RetryConfig controls in-process retries of transient database errors
+type RetryConfig struct {
+	MaxAttempts  int           `env:"RETRY_MAX_ATTEMPTS" env-default:"3"`
+	InitialDelay time.Duration `env:"RETRY_INITIAL_DELAY" env-default:"100ms"`
+	MaxDelay     time.Duration `env:"RETRY_MAX_DELAY" env-default:"2s"`
+	Multiplier   float64       `env:"RETRY_MULTIPLIER" env-default:"2"`
+}
+
+// WarmupConfig controls how the order cache is restored from the database on startup
+type WarmupConfig struct {
+	Enabled   bool          `env:"CACHE_WARMUP_ENABLED" env-default:"true"`
+	Limit     int           `env:"CACHE_WARMUP_LIMIT" env-default:"1000"`
+	MaxAge    time.Duration `env:"CACHE_WARMUP_MAX_AGE" env-default:"0s"`
+	BatchSize int           `env:"CACHE_WARMUP_BATCH_SIZE" env-default:"100"`
+}
+
+// DeadLetterConfig controls where orders that failed processing are published
+type DeadLetterConfig struct {
+	Subject string        `env:"NATS_DLQ_SUBJECT" env-default:"dlq.orders"`
+	Stream  string        `env:"NATS_DLQ_STREAM" env-default:"ORDERS_DLQ"`
+	MaxAge  time.Duration `env:"NATS_DLQ_MAX_AGE" env-default:"720h"`
+}
+
+// AuthConfig holds JWT signing keys as kid:secret pairs, or kid:path pairs of
+// files holding the secret or a PEM encoded RSA, ECDSA or Ed25519 key. New
+// tokens are signed with SigningKeyID, the first configured key by default,
+// the other keys only verify. Without keys the app refuses to start unless
+// DevMode is set.
+type AuthConfig struct {
+	DevMode         bool     `env:"AUTH_DEV_MODE" env-default:"false"`
+	Keys            []string `env:"JWT_KEYS"`
+	KeyFiles        []string `env:"JWT_KEY_FILES"`
+	PrivateKeyFiles []string `env:"JWT_PRIVATE_KEY_FILES"`
+	PublicKeyFiles  []string `env:"JWT_PUBLIC_KEY_FILES"`
+	SigningKeyID    string   `env:"JWT_SIGNING_KEY_ID"`
+
+	AccessTTL  time.Duration `env:"JWT_ACCESS_TTL" env-default:"15m"`
+	RefreshTTL time.Duration `env:"JWT_REFRESH_TTL" env-default:"720h"`
+
+	// LoginMaxAttempts failed logins in a row lock the user for LoginLockout
+	LoginMaxAttempts int           `env:"AUTH_LOGIN_MAX_ATTEMPTS" env-default:"5"`
+	LoginLockout     time.Duration `env:"AUTH_LOGIN_LOCKOUT" env-default:"15m"`
+}
+
+// TracingConfig controls OpenTelemetry tracing. Exporter is none, stdout or
+// otlp, the OTLP exporter sends over HTTP to OTLPEndpoint.
+type TracingConfig struct {
+	Exporter     string  `env:"TRACING_EXPORTER" env-default:"none"`
+	OTLPEndpoint string  `env:"TRACING_OTLP_ENDPOINT" env-default:"localhost:4318"`
+	OTLPInsecure bool    `env:"TRACING_OTLP_INSECURE" env-default:"true"`
+	ServiceName  string  `env:"TRACING_SERVICE_NAME" env-default:"wb-app"`
+	SampleRatio  float64 `env:"TRACING_SAMPLE_RATIO" env-default:"1"`
 }
 
 func Load() (*Config, error) {
@@ -42,5 +140,23 @@ func Load() (*Config, error) {
 		return nil, err
 	}
 
+	if err := cfg.validate(); err != nil {
+		return nil, err
+	}
+
 	return cfg, nil
 }
+
+// validate rejects values cleanenv parses fine but the app cannot use
+func (c *Config) validate() error {
+	if c.Warmup.Limit < 0 {
+		return fmt.Errorf("CACHE_WARMUP_LIMIT must not be negative, got %d", c.Warmup.Limit)
+	}
+	if c.Warmup.BatchSize < 0 {
+		return fmt.Errorf("CACHE_WARMUP_BATCH_SIZE must not be negative, got %d", c.Warmup.BatchSize)
+	}
+	if c.Auth.LoginMaxAttempts < 1 {
+		return fmt.Errorf("AUTH_LOGIN_MAX_ATTEMPTS must be at least 1, got %d", c.Auth.LoginMaxAttempts)
+	}
+	return nil
+}
@@ -23,6 +23,8 @@ func NewPostgres(ctx context.Context, dsn string) (*PostgresClient, error) {
 	config.MinConns = 5
 	config.MaxConnLifetime = time.Hour
 	config.MaxConnIdleTime = time.Minute * 30
+	// Trace statements as children of the caller's span
+	config.ConnConfig.Tracer = newQueryTracer()
 
 	pool, err := pgxpool.NewWithConfig(ctx, config)
 	if err != nil {
@@ -0,0 +1,65 @@
+package db
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+)
+
+var (
+	_ pgx.QueryTracer    = (*queryTracer)(nil)
+	_ pgx.BatchTracer    = (*queryTracer)(nil)
+	_ pgx.CopyFromTracer = (*queryTracer)(nil)
+)
+
+func newRecordingTracer() (*queryTracer, *tracetest.SpanRecorder) {
+	recorder := tracetest.NewSpanRecorder()
+	provider := sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(recorder))
+	return &queryTracer{tracer: provider.Tracer(tracerName)}, recorder
+}
+
+func TestBatchIsTracedAsOneSpan(t *testing.T) {
+	tracer, recorder := newRecordingTracer()
+
+	batch := &pgx.Batch{}
+	batch.Queue("INSERT INTO orders VALUES ($1)", "a")
+	batch.Queue("INSERT INTO orders VALUES ($1)", "b")
+
+	ctx := tracer.TraceBatchStart(context.Background(), nil, pgx.TraceBatchStartData{Batch: batch})
+	tracer.TraceBatchQuery(ctx, nil, pgx.TraceBatchQueryData{SQL: "INSERT INTO orders VALUES ($1)", CommandTag: pgconn.NewCommandTag("INSERT 0 1")})
+	tracer.TraceBatchQuery(ctx, nil, pgx.TraceBatchQueryData{SQL: "INSERT INTO orders VALUES ($1)", Err: errors.New("duplicate key")})
+	tracer.TraceBatchEnd(ctx, nil, pgx.TraceBatchEndData{Err: errors.New("duplicate key")})
+
+	spans := recorder.Ended()
+	if len(spans) != 1 {
+		t.Fatalf("expected one span, got %d", len(spans))
+	}
+	span := spans[0]
+	if span.Name() != "postgres.batch" {
+		t.Errorf("unexpected span name %q", span.Name())
+	}
+	if len(span.Events()) != 3 {
+		// Two queued statements plus the recorded error
+		t.Errorf("expected 3 events, got %d", len(span.Events()))
+	}
+	if span.Status().Description != "duplicate key" {
+		t.Errorf("expected the batch error as status, got %q", span.Status().Description)
+	}
+}
+
+func TestCopyFromIsTraced(t *testing.T) {
+	tracer, recorder := newRecordingTracer()
+
+	ctx := tracer.TraceCopyFromStart(context.Background(), nil, pgx.TraceCopyFromStartData{TableName: pgx.Identifier{"items"}})
+	tracer.TraceCopyFromEnd(ctx, nil, pgx.TraceCopyFromEndData{CommandTag: pgconn.NewCommandTag("COPY 3")})
+
+	spans := recorder.Ended()
+	if len(spans) != 1 || spans[0].Name() != "postgres.copy_from" {
+		t.Fatalf("expected a copy_from span, got %v", spans)
+	}
+}
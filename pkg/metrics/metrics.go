@@ -0,0 +1,98 @@
+package metrics
+
+import (
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+)
+
+const namespace = "wb"
+
+// Consumer
+var (
+	MessagesReceived = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Subsystem: "consumer",
+		Name:      "messages_received_total",
+		Help:      "Order messages received from NATS.",
+	}, []string{"subject"})
+
+	MessagesProcessed = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Subsystem: "consumer",
+		Name:      "messages_processed_total",
+		Help:      "Order messages stored successfully.",
+	}, []string{"subject"})
+
+	// MessagesFailed counts failed messages by outcome, dead_lettered, redelivered
+	// or dropped (core NATS only, when the dead-letter queue rejects them)
+	MessagesFailed = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Subsystem: "consumer",
+		Name:      "messages_failed_total",
+		Help:      "Order messages that failed processing, by outcome.",
+	}, []string{"subject", "outcome"})
+
+	ProcessingDuration = promauto.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: namespace,
+		Subsystem: "consumer",
+		Name:      "processing_duration_seconds",
+		Help:      "Time spent storing orders, by operation.",
+		Buckets:   prometheus.DefBuckets,
+	}, []string{"operation"})
+)
+
+// Cache
+var (
+	// CacheRequests counts order lookups by cache tier and result, hit, miss or error
+	CacheRequests = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Subsystem: "order_cache",
+		Name:      "requests_total",
+		Help:      "Order cache lookups by tier and result.",
+	}, []string{"cache", "result"})
+
+	CacheErrors = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Subsystem: "order_cache",
+		Name:      "errors_total",
+		Help:      "Failed Redis order cache operations.",
+	}, []string{"operation"})
+)
+
+// Database
+var DBQueryDuration = promauto.NewHistogramVec(prometheus.HistogramOpts{
+	Namespace: namespace,
+	Subsystem: "db",
+	Name:      "query_duration_seconds",
+	Help:      "Latency of order repository operations.",
+	Buckets:   prometheus.DefBuckets,
+}, []string{"query"})
+
+// HTTP
+var HTTPRequestDuration = promauto.NewHistogramVec(prometheus.HistogramOpts{
+	Namespace: namespace,
+	Subsystem: "http",
+	Name:      "request_duration_seconds",
+	Help:      "HTTP request latency by method, route template and status.",
+	Buckets:   prometheus.DefBuckets,
+}, []string{"method", "route", "status"})
+
+// Cache lookup results
+const (
+	CacheHit   = "hit"
+	CacheMiss  = "miss"
+	CacheError = "error"
+)
+
+// ObserveDBQuery records the latency of a repository operation started at
+// start, meant to be deferred: defer metrics.ObserveDBQuery("get_order", time.Now())
+func ObserveDBQuery(query string, start time.Time) {
+	DBQueryDuration.WithLabelValues(query).Observe(time.Since(start).Seconds())
+}
+
+// ObserveProcessing records how long storing orders took
+func ObserveProcessing(operation string, start time.Time) {
+	ProcessingDuration.WithLabelValues(operation).Observe(time.Since(start).Seconds())
+}
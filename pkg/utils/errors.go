@@ -1 +1,36 @@
 package utils
+
+import "errors"
+
+// permanentError marks an error that retrying will not fix
+type permanentError struct {
+	err error
+}
+
+func (e *permanentError) Error() string {
+	return e.err.Error()
+}
+
+func (e *permanentError) Unwrap() error {
+	return e.err
+}
+
+// Permanent marks err as permanent, nil stays nil
+func Permanent(err error) error {
+	if err == nil {
+		return nil
+	}
+	return &permanentError{err: err}
+}
+
+// IsPermanent reports whether err (or anything it wraps) was marked permanent
+func IsPermanent(err error) bool {
+	var pe *permanentError
+	return errors.As(err, &pe)
+}
+
+// IsTransient reports whether err may succeed on retry. Unclassified errors
+// are considered transient.
+func IsTransient(err error) bool {
+	return err != nil && !IsPermanent(err)
+}
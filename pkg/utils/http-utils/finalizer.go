@@ -60,3 +60,21 @@ func WriteResponse(w http.ResponseWriter, status int, message string, err error,
 
 	return statusResponse
 }
+
+// WriteErrorDetails writes an error response carrying per-field details
+func WriteErrorDetails(w http.ResponseWriter, status int, message string, details []ErrorDetail) ErrorResponse {
+	w.Header().Set("Content-Type", "application/json; charset=utf-8")
+	errorResponse := ErrorResponse{
+		Status:       status,
+		Message:      message,
+		Details:      details,
+		LogTimestamp: time.Now().Format(time.RFC3339),
+		RequestID:    uuid.New().String(),
+	}
+
+	w.WriteHeader(status)
+	v, _ := jsoniter.Marshal(errorResponse)
+	w.Write(v)
+
+	return errorResponse
+}
@@ -0,0 +1,68 @@
+package utils
+
+import (
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"sort"
+)
+
+// JSONChange is a value that differs between two JSON documents. Path uses
+// dots for object keys and [i] for array indexes, e.g. items[0].price.
+type JSONChange struct {
+	Path string
+	From any
+	To   any
+}
+
+// DiffJSON compares two JSON documents leaf by leaf. A leaf missing on one
+// side is reported with a nil value. Changes are sorted by path.
+func DiffJSON(a, b []byte) ([]JSONChange, error) {
+	var left, right any
+	if err := json.Unmarshal(a, &left); err != nil {
+		return nil, fmt.Errorf("failed to decode left document: %w", err)
+	}
+	if err := json.Unmarshal(b, &right); err != nil {
+		return nil, fmt.Errorf("failed to decode right document: %w", err)
+	}
+
+	leftLeaves := map[string]any{}
+	rightLeaves := map[string]any{}
+	flattenJSON("", left, leftLeaves)
+	flattenJSON("", right, rightLeaves)
+
+	var changes []JSONChange
+	for path, from := range leftLeaves {
+		to, ok := rightLeaves[path]
+		if !ok || !reflect.DeepEqual(from, to) {
+			changes = append(changes, JSONChange{Path: path, From: from, To: to})
+		}
+	}
+	for path, to := range rightLeaves {
+		if _, ok := leftLeaves[path]; !ok {
+			changes = append(changes, JSONChange{Path: path, From: nil, To: to})
+		}
+	}
+
+	sort.Slice(changes, func(i, j int) bool { return changes[i].Path < changes[j].Path })
+	return changes, nil
+}
+
+func flattenJSON(prefix string, v any, out map[string]any) {
+	switch val := v.(type) {
+	case map[string]any:
+		for k, child := range val {
+			path := k
+			if prefix != "" {
+				path = prefix + "." + k
+			}
+			flattenJSON(path, child, out)
+		}
+	case []any:
+		for i, child := range val {
+			flattenJSON(fmt.Sprintf("%s[%d]", prefix, i), child, out)
+		}
+	default:
+		out[prefix] = val
+	}
+}
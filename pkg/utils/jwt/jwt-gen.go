@@ -3,8 +3,8 @@ package jwt
 import (
 	"context"
 	"errors"
-	"fmt"
 	"net/http"
+	"slices"
 	"strings"
 	"time"
 
@@ -12,58 +12,65 @@ import (
 )
 
 var (
-	JWTSecret       = []byte("TheSecretKeyAlwayssafe")
+	// DevSecret signs tokens of the development key set, never use it outside dev mode
+	DevSecret       = []byte("TheSecretKeyAlwayssafe")
 	ErrInvalidToken = errors.New("invalid token")
 	ErrExpiredToken = errors.New("token expired")
 	ErrMissingToken = errors.New("missing token")
+	ErrRevokedToken = errors.New("token revoked")
 )
 
+// devKeys backs the package-level helpers
+var devKeys = NewDevKeySet()
+
+// claimsKey is the context key claims of an authenticated request are stored under
+type claimsKey struct{}
+
 // Claims represents the JWT claims structure
 type Claims struct {
-	UserID   int    `json:"user_id"`
-	Username string `json:"username"`
+	UserID   int      `json:"user_id"`
+	Username string   `json:"username"`
+	Roles    []string `json:"roles,omitempty"`
+	Scopes   []string `json:"scopes,omitempty"`
 	jwt.RegisteredClaims
 }
 
-// GenerateJWT creates a new JWT token with user information
-func GenerateJWT(userID int, username string) (string, error) {
-	claims := Claims{
-		UserID:   userID,
-		Username: username,
-		RegisteredClaims: jwt.RegisteredClaims{
-			ExpiresAt: jwt.NewNumericDate(time.Now().Add(24 * time.Hour)),
-			IssuedAt:  jwt.NewNumericDate(time.Now()),
-			NotBefore: jwt.NewNumericDate(time.Now()),
-			Issuer:    "wb-app",
-			Subject:   fmt.Sprintf("%d", userID),
-		},
-	}
+// HasRole reports whether the token carries the role
+func (c *Claims) HasRole(role string) bool {
+	return slices.Contains(c.Roles, role)
+}
 
-	token := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
-	return token.SignedString(JWTSecret)
+// HasScope reports whether the token carries the scope
+func (c *Claims) HasScope(scope string) bool {
+	return slices.Contains(c.Scopes, scope)
 }
 
-// ParseJWT validates and parses a JWT token
-func ParseJWT(tokenString string) (*Claims, error) {
-	token, err := jwt.ParseWithClaims(tokenString, &Claims{}, func(token *jwt.Token) (interface{}, error) {
-		if _, ok := token.Method.(*jwt.SigningMethodHMAC); !ok {
-			return nil, fmt.Errorf("unexpected signing method: %v", token.Header["alg"])
-		}
-		return JWTSecret, nil
-	})
-
-	if err != nil {
-		if errors.Is(err, jwt.ErrTokenExpired) {
-			return nil, ErrExpiredToken
-		}
-		return nil, ErrInvalidToken
-	}
+// Option sets optional claims of a generated token
+type Option func(*Claims)
 
-	if claims, ok := token.Claims.(*Claims); ok && token.Valid {
-		return claims, nil
-	}
+// WithRoles sets the roles of the token
+func WithRoles(roles ...string) Option {
+	return func(c *Claims) { c.Roles = roles }
+}
+
+// WithScopes sets the scopes of the token
+func WithScopes(scopes ...string) Option {
+	return func(c *Claims) { c.Scopes = scopes }
+}
 
-	return nil, ErrInvalidToken
+// WithTTL sets how long the token is valid, 24 hours by default
+func WithTTL(ttl time.Duration) Option {
+	return func(c *Claims) { c.ExpiresAt = jwt.NewNumericDate(c.IssuedAt.Add(ttl)) }
+}
+
+// GenerateJWT creates a new JWT token with user information, signed with the development key set
+func GenerateJWT(userID int, username string, opts ...Option) (string, error) {
+	return devKeys.GenerateJWT(userID, username, opts...)
+}
+
+// ParseJWT validates and parses a JWT token against the development key set
+func ParseJWT(tokenString string) (*Claims, error) {
+	return devKeys.ParseJWT(tokenString)
 }
 
 // ExtractTokenFromHeader extracts JWT token from Authorization header
@@ -86,46 +93,17 @@ func ExtractTokenFromHeader(r *http.Request) (string, error) {
 	return token, nil
 }
 
+// ContextWithUser returns a copy of ctx carrying the user claims
+func ContextWithUser(ctx context.Context, claims *Claims) context.Context {
+	return context.WithValue(ctx, claimsKey{}, claims)
+}
+
 // GetUserFromContext extracts user claims from request context
 func GetUserFromContext(ctx context.Context) (*Claims, bool) {
-	user, ok := ctx.Value("user").(*Claims)
+	user, ok := ctx.Value(claimsKey{}).(*Claims)
 	return user, ok
 }
 
-// RefreshToken generates a new token with extended expiration
-func RefreshToken(tokenString string) (string, error) {
-	claims, err := ParseJWT(tokenString)
-	if err != nil {
-		return "", err
-	}
-
-	// Calculate new expiration: extend from original expiration time
-	var newExpiration time.Time
-	if claims.ExpiresAt != nil {
-		// Extend by 24 hours from the original expiration
-		newExpiration = claims.ExpiresAt.Time.Add(24 * time.Hour)
-	} else {
-		// Fallback: 24 hours from now
-		newExpiration = time.Now().Add(24 * time.Hour)
-	}
-
-	// Create new claims with extended expiration
-	newClaims := Claims{
-		UserID:   claims.UserID,
-		Username: claims.Username,
-		RegisteredClaims: jwt.RegisteredClaims{
-			ExpiresAt: jwt.NewNumericDate(newExpiration),
-			IssuedAt:  jwt.NewNumericDate(time.Now()),
-			NotBefore: jwt.NewNumericDate(time.Now()),
-			Issuer:    "wb-app",
-			Subject:   fmt.Sprintf("%d", claims.UserID),
-		},
-	}
-
-	token := jwt.NewWithClaims(jwt.SigningMethodHS256, newClaims)
-	return token.SignedString(JWTSecret)
-}
-
 // ValidateToken validates token without parsing claims
 func ValidateToken(tokenString string) error {
 	_, err := ParseJWT(tokenString)
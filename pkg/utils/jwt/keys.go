@@ -0,0 +1,273 @@
+package jwt
+
+import (
+	"errors"
+	"fmt"
+	"log/slog"
+	"os"
+	"strings"
+	"time"
+
+	"wb-test/pkg/config"
+
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/google/uuid"
+)
+
+const (
+	devKeyID = "dev"
+
+	// minSecretLength is the shortest HS256 secret accepted from config
+	minSecretLength = 32
+)
+
+// Key is a named signing key. HMAC keys use the secret for both signing and
+// verification, asymmetric keys sign with the private key and verify with the
+// public one. A key without a private part only verifies.
+type Key struct {
+	ID      string
+	Method  jwt.SigningMethod
+	Private any
+	Public  any
+}
+
+// HMACKey returns an HS256 key
+func HMACKey(kid string, secret []byte) Key {
+	return Key{ID: kid, Method: jwt.SigningMethodHS256, Private: secret, Public: secret}
+}
+
+func (k Key) isHMAC() bool {
+	_, ok := k.Method.(*jwt.SigningMethodHMAC)
+	return ok
+}
+
+// Denylist reports access tokens revoked before they expire, by jti
+type Denylist interface {
+	IsTokenDenied(jti string) (bool, error)
+}
+
+// KeySet signs tokens with one key and verifies them with any of its keys,
+// picked by the kid header. Keeping the previous key in the set lets tokens
+// it signed verify until they expire, which is how keys are rotated.
+type KeySet struct {
+	keys       map[string]Key
+	order      []string
+	signingKID string
+	denylist   Denylist
+}
+
+// NewKeySet creates a key set signing with the key of signingKID. An empty
+// signingKID makes a verify-only set.
+func NewKeySet(signingKID string, keys ...Key) (*KeySet, error) {
+	if len(keys) == 0 {
+		return nil, errors.New("no keys")
+	}
+
+	set := &KeySet{keys: make(map[string]Key, len(keys)), signingKID: signingKID}
+	for _, key := range keys {
+		if key.ID == "" {
+			return nil, errors.New("empty key id")
+		}
+		if _, ok := set.keys[key.ID]; ok {
+			return nil, fmt.Errorf("duplicate key id %q", key.ID)
+		}
+		set.keys[key.ID] = key
+		set.order = append(set.order, key.ID)
+	}
+
+	if signingKID != "" {
+		key, ok := set.keys[signingKID]
+		if !ok {
+			return nil, fmt.Errorf("signing key %q is not configured", signingKID)
+		}
+		if key.Private == nil {
+			return nil, fmt.Errorf("signing key %q has no private key", signingKID)
+		}
+	}
+	return set, nil
+}
+
+// SetDenylist makes ParseJWT reject revoked tokens, call it before the set is in use
+func (k *KeySet) SetDenylist(denylist Denylist) {
+	k.denylist = denylist
+}
+
+// NewDevKeySet returns a key set holding only DevSecret
+func NewDevKeySet() *KeySet {
+	set, _ := NewKeySet(devKeyID, HMACKey(devKeyID, DevSecret))
+	return set
+}
+
+// LoadKeySet builds the key set from config: HMAC secrets from env or files
+// and PEM encoded RSA, ECDSA or Ed25519 keys. Without configured keys it fails
+// unless dev mode is on, in which case the development key set is used.
+func LoadKeySet(cfg config.AuthConfig) (*KeySet, error) {
+	var keys []Key
+
+	addSecret := func(kid string, secret []byte) error {
+		if len(secret) < minSecretLength {
+			return fmt.Errorf("key %q is shorter than %d bytes", kid, minSecretLength)
+		}
+		keys = append(keys, HMACKey(kid, secret))
+		return nil
+	}
+
+	for _, entry := range cfg.Keys {
+		kid, secret, ok := strings.Cut(entry, ":")
+		if !ok {
+			return nil, errors.New("invalid JWT key, expected kid:secret")
+		}
+		if err := addSecret(kid, []byte(secret)); err != nil {
+			return nil, fmt.Errorf("invalid JWT key: %w", err)
+		}
+	}
+
+	for _, entry := range cfg.KeyFiles {
+		kid, data, err := readKeyFile(entry)
+		if err != nil {
+			return nil, err
+		}
+		if err := addSecret(kid, []byte(strings.TrimSpace(string(data)))); err != nil {
+			return nil, fmt.Errorf("invalid JWT key file: %w", err)
+		}
+	}
+
+	for _, entry := range cfg.PrivateKeyFiles {
+		kid, data, err := readKeyFile(entry)
+		if err != nil {
+			return nil, err
+		}
+		key, err := ParsePrivateKeyPEM(kid, data)
+		if err != nil {
+			return nil, fmt.Errorf("invalid JWT private key file: %w", err)
+		}
+		keys = append(keys, key)
+	}
+
+	for _, entry := range cfg.PublicKeyFiles {
+		kid, data, err := readKeyFile(entry)
+		if err != nil {
+			return nil, err
+		}
+		key, err := ParsePublicKeyPEM(kid, data)
+		if err != nil {
+			return nil, fmt.Errorf("invalid JWT public key file: %w", err)
+		}
+		keys = append(keys, key)
+	}
+
+	if len(keys) == 0 {
+		if !cfg.DevMode {
+			return nil, errors.New("no JWT signing keys configured, set JWT_KEYS, JWT_KEY_FILES or JWT_PRIVATE_KEY_FILES")
+		}
+		slog.Warn("No JWT signing keys configured, using the development key")
+		return NewDevKeySet(), nil
+	}
+
+	signingKID := cfg.SigningKeyID
+	if signingKID == "" {
+		// Default to the first key able to sign
+		for _, key := range keys {
+			if key.Private != nil {
+				signingKID = key.ID
+				break
+			}
+		}
+	}
+	return NewKeySet(signingKID, keys...)
+}
+
+// readKeyFile reads a kid:path entry
+func readKeyFile(entry string) (string, []byte, error) {
+	kid, path, ok := strings.Cut(entry, ":")
+	if !ok {
+		return "", nil, fmt.Errorf("invalid JWT key file %q, expected kid:path", entry)
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return "", nil, fmt.Errorf("failed to read JWT key file: %w", err)
+	}
+	return kid, data, nil
+}
+
+// GenerateJWT creates a new JWT token with user information
+func (k *KeySet) GenerateJWT(userID int, username string, opts ...Option) (string, error) {
+	claims := newClaims(userID, username, time.Now().Add(24*time.Hour))
+	for _, opt := range opts {
+		opt(&claims)
+	}
+	return k.sign(claims)
+}
+
+// ParseJWT validates and parses a JWT token. The kid header picks the key and
+// the token algorithm must match it. Tokens without a kid header are verified
+// with the signing key.
+func (k *KeySet) ParseJWT(tokenString string) (*Claims, error) {
+	token, err := jwt.ParseWithClaims(tokenString, &Claims{}, func(token *jwt.Token) (interface{}, error) {
+		kid, _ := token.Header["kid"].(string)
+		if kid == "" {
+			kid = k.signingKID
+		}
+		key, ok := k.keys[kid]
+		if !ok {
+			return nil, fmt.Errorf("unknown key id %q", kid)
+		}
+		if token.Method.Alg() != key.Method.Alg() {
+			return nil, fmt.Errorf("unexpected signing method: %v", token.Header["alg"])
+		}
+		return key.Public, nil
+	})
+
+	if err != nil {
+		if errors.Is(err, jwt.ErrTokenExpired) {
+			return nil, ErrExpiredToken
+		}
+		return nil, ErrInvalidToken
+	}
+
+	claims, ok := token.Claims.(*Claims)
+	if !ok || !token.Valid {
+		return nil, ErrInvalidToken
+	}
+
+	if k.denylist != nil && claims.ID != "" {
+		denied, err := k.denylist.IsTokenDenied(claims.ID)
+		if err != nil {
+			// Fail closed, a revoked token must never pass
+			slog.Error("Failed to check token denylist", "error", err, "jti", claims.ID)
+			return nil, ErrInvalidToken
+		}
+		if denied {
+			return nil, ErrRevokedToken
+		}
+	}
+
+	return claims, nil
+}
+
+func newClaims(userID int, username string, expiresAt time.Time) Claims {
+	return Claims{
+		UserID:   userID,
+		Username: username,
+		RegisteredClaims: jwt.RegisteredClaims{
+			ID:        uuid.NewString(),
+			ExpiresAt: jwt.NewNumericDate(expiresAt),
+			IssuedAt:  jwt.NewNumericDate(time.Now()),
+			NotBefore: jwt.NewNumericDate(time.Now()),
+			Issuer:    "wb-app",
+			Subject:   fmt.Sprintf("%d", userID),
+		},
+	}
+}
+
+// sign signs claims with the signing key and records its kid in the header
+func (k *KeySet) sign(claims Claims) (string, error) {
+	if k.signingKID == "" {
+		return "", errors.New("key set has no signing key")
+	}
+	key := k.keys[k.signingKID]
+
+	token := jwt.NewWithClaims(key.Method, claims)
+	token.Header["kid"] = key.ID
+	return token.SignedString(key.Private)
+}
@@ -0,0 +1,104 @@
+package jwt
+
+import (
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/elliptic"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/pem"
+	"errors"
+	"fmt"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+// ParsePrivateKeyPEM reads a PKCS#8, PKCS#1 (RSA) or SEC 1 (EC) private key.
+// The algorithm follows the key type: RS256 for RSA, ES256/ES384/ES512 for
+// P-256/P-384/P-521 and EdDSA for Ed25519.
+func ParsePrivateKeyPEM(kid string, data []byte) (Key, error) {
+	block, _ := pem.Decode(data)
+	if block == nil {
+		return Key{}, errors.New("no PEM data found")
+	}
+
+	var private crypto.Signer
+	switch block.Type {
+	case "RSA PRIVATE KEY":
+		key, err := x509.ParsePKCS1PrivateKey(block.Bytes)
+		if err != nil {
+			return Key{}, fmt.Errorf("failed to parse RSA private key: %w", err)
+		}
+		private = key
+	case "EC PRIVATE KEY":
+		key, err := x509.ParseECPrivateKey(block.Bytes)
+		if err != nil {
+			return Key{}, fmt.Errorf("failed to parse EC private key: %w", err)
+		}
+		private = key
+	default:
+		key, err := x509.ParsePKCS8PrivateKey(block.Bytes)
+		if err != nil {
+			return Key{}, fmt.Errorf("failed to parse private key: %w", err)
+		}
+		signer, ok := key.(crypto.Signer)
+		if !ok {
+			return Key{}, fmt.Errorf("unsupported private key type %T", key)
+		}
+		private = signer
+	}
+
+	key, err := asymmetricKey(kid, private.Public())
+	if err != nil {
+		return Key{}, err
+	}
+	key.Private = private
+	return key, nil
+}
+
+// ParsePublicKeyPEM reads a PKIX or PKCS#1 (RSA) public key, the key only verifies
+func ParsePublicKeyPEM(kid string, data []byte) (Key, error) {
+	block, _ := pem.Decode(data)
+	if block == nil {
+		return Key{}, errors.New("no PEM data found")
+	}
+
+	var public any
+	var err error
+	if block.Type == "RSA PUBLIC KEY" {
+		public, err = x509.ParsePKCS1PublicKey(block.Bytes)
+	} else {
+		public, err = x509.ParsePKIXPublicKey(block.Bytes)
+	}
+	if err != nil {
+		return Key{}, fmt.Errorf("failed to parse public key: %w", err)
+	}
+
+	return asymmetricKey(kid, public)
+}
+
+// asymmetricKey picks the signing method for a public key
+func asymmetricKey(kid string, public any) (Key, error) {
+	key := Key{ID: kid, Public: public}
+	switch pub := public.(type) {
+	case *rsa.PublicKey:
+		key.Method = jwt.SigningMethodRS256
+	case *ecdsa.PublicKey:
+		switch pub.Curve {
+		case elliptic.P256():
+			key.Method = jwt.SigningMethodES256
+		case elliptic.P384():
+			key.Method = jwt.SigningMethodES384
+		case elliptic.P521():
+			key.Method = jwt.SigningMethodES512
+		default:
+			return Key{}, fmt.Errorf("unsupported curve %s", pub.Curve.Params().Name)
+		}
+	case ed25519.PublicKey:
+		key.Method = jwt.SigningMethodEdDSA
+	default:
+		return Key{}, fmt.Errorf("unsupported public key type %T", public)
+	}
+	return key, nil
+}
@@ -0,0 +1,61 @@
+package utils
+
+import (
+	"context"
+	"math/rand/v2"
+	"time"
+)
+
+// Backoff describes an exponential backoff with full jitter
+type Backoff struct {
+	MaxAttempts  int
+	InitialDelay time.Duration
+	MaxDelay     time.Duration
+	Multiplier   float64
+}
+
+// Delay returns a random wait before the given retry (1-based), capped at MaxDelay
+func (b Backoff) Delay(retry int) time.Duration {
+	multiplier := b.Multiplier
+	if multiplier < 1 {
+		multiplier = 2
+	}
+
+	ceiling := float64(b.InitialDelay)
+	for i := 1; i < retry; i++ {
+		ceiling *= multiplier
+		if b.MaxDelay > 0 && ceiling >= float64(b.MaxDelay) {
+			ceiling = float64(b.MaxDelay)
+			break
+		}
+	}
+	if ceiling <= 0 {
+		return 0
+	}
+
+	return time.Duration(rand.Int64N(int64(ceiling)) + 1)
+}
+
+// Retry calls fn until it succeeds, returns a permanent error, the attempts are
+// used up or ctx is done. onRetry, if set, is called before each wait.
+func Retry(ctx context.Context, b Backoff, fn func() error, onRetry func(attempt int, delay time.Duration, err error)) error {
+	attempts := max(b.MaxAttempts, 1)
+
+	var err error
+	for attempt := 1; ; attempt++ {
+		if err = fn(); err == nil || IsPermanent(err) || attempt >= attempts {
+			return err
+		}
+
+		delay := b.Delay(attempt)
+		if onRetry != nil {
+			onRetry(attempt, delay, err)
+		}
+
+		select {
+		case <-ctx.Done():
+			return err
+		case <-time.After(delay):
+		}
+	}
+}
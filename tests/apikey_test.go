@@ -0,0 +1,181 @@
+package tests
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	handler "wb-test/internal/handlers"
+	"wb-test/internal/handlers/middleware"
+	"wb-test/internal/models"
+	apikeyservice "wb-test/internal/service/apikey"
+	"wb-test/pkg/utils/jwt"
+)
+
+type memoryAPIKeyRepo struct {
+	mu      sync.Mutex
+	keys    []*models.APIKey
+	touches int
+}
+
+func (r *memoryAPIKeyRepo) CreateAPIKey(key *models.APIKey) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	key.ID = len(r.keys) + 1
+	key.CreatedAt = time.Now()
+	stored := *key
+	r.keys = append(r.keys, &stored)
+	return nil
+}
+
+func (r *memoryAPIKeyRepo) GetAPIKeyByHash(hash string) (*models.APIKey, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for _, key := range r.keys {
+		if key.KeyHash == hash {
+			found := *key
+			return &found, nil
+		}
+	}
+	return nil, models.ErrAPIKeyNotFound
+}
+
+func (r *memoryAPIKeyRepo) ListAPIKeys() ([]*models.APIKey, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	keys := []*models.APIKey{}
+	for i := len(r.keys) - 1; i >= 0; i-- {
+		key := *r.keys[i]
+		keys = append(keys, &key)
+	}
+	return keys, nil
+}
+
+func (r *memoryAPIKeyRepo) RevokeAPIKey(id int) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if id < 1 || id > len(r.keys) {
+		return models.ErrAPIKeyNotFound
+	}
+	now := time.Now()
+	r.keys[id-1].RevokedAt = &now
+	return nil
+}
+
+func (r *memoryAPIKeyRepo) TouchAPIKey(id int) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	now := time.Now()
+	r.keys[id-1].LastUsedAt = &now
+	r.touches++
+	return nil
+}
+
+func TestAPIKeyLifecycle(t *testing.T) {
+	repo := &memoryAPIKeyRepo{}
+	service := apikeyservice.NewAPIKeyService(repo)
+
+	created, err := service.CreateAPIKey("billing-batch", []string{"orders:read"}, nil)
+	require.NoError(t, err)
+	assert.True(t, strings.HasPrefix(created.Key, "wbk_"))
+	assert.True(t, strings.HasPrefix(created.Key, created.Prefix))
+	assert.NotContains(t, repo.keys[0].KeyHash, created.Key)
+
+	key, err := service.AuthenticateAPIKey(created.Key)
+	require.NoError(t, err)
+	assert.Equal(t, "billing-batch", key.Name)
+	assert.Equal(t, []string{"orders:read"}, key.Scopes)
+
+	// Last use is written at most once a minute
+	_, err = service.AuthenticateAPIKey(created.Key)
+	require.NoError(t, err)
+	assert.Equal(t, 1, repo.touches)
+
+	_, err = service.AuthenticateAPIKey("wbk_unknown")
+	assert.ErrorIs(t, err, models.ErrInvalidAPIKey)
+
+	require.NoError(t, service.RevokeAPIKey(created.ID))
+	_, err = service.AuthenticateAPIKey(created.Key)
+	assert.ErrorIs(t, err, models.ErrInvalidAPIKey)
+	assert.ErrorIs(t, service.RevokeAPIKey(42), models.ErrAPIKeyNotFound)
+}
+
+func TestAPIKeyExpiry(t *testing.T) {
+	repo := &memoryAPIKeyRepo{}
+	service := apikeyservice.NewAPIKeyService(repo)
+
+	past := time.Now().Add(-time.Hour)
+	_, err := service.CreateAPIKey("expired", nil, &past)
+	assert.Error(t, err)
+
+	future := time.Now().Add(time.Hour)
+	created, err := service.CreateAPIKey("short-lived", nil, &future)
+	require.NoError(t, err)
+
+	// Expire the stored key
+	repo.keys[0].ExpiresAt = &past
+	_, err = service.AuthenticateAPIKey(created.Key)
+	assert.ErrorIs(t, err, models.ErrInvalidAPIKey)
+}
+
+func TestAPIKeyEndpoints(t *testing.T) {
+	service := apikeyservice.NewAPIKeyService(&memoryAPIKeyRepo{})
+	orders := &stubOrderService{orders: map[string]*models.Order{"b563feb7b2b84b6test": {OrderUID: "b563feb7b2b84b6test"}}}
+	router := handler.InitRouter(handler.NewHandler(orders, nil, jwt.NewDevKeySet(), nil, service))
+
+	admin, err := jwt.GenerateJWT(1, "admin", jwt.WithRoles(middleware.RoleAdmin))
+	require.NoError(t, err)
+	reader, err := jwt.GenerateJWT(2, "reader", jwt.WithScopes(middleware.ScopeOrdersRead))
+	require.NoError(t, err)
+
+	do := func(method, path string, body []byte, header, value string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(method, path, bytes.NewReader(body))
+		req.Header.Set(header, value)
+		rr := httptest.NewRecorder()
+		router.ServeHTTP(rr, req)
+		return rr
+	}
+
+	body, _ := json.Marshal(models.CreateAPIKeyRequest{Name: "partner", Scopes: []string{middleware.ScopeOrdersRead}})
+
+	// Only admins manage keys
+	rr := do(http.MethodPost, "/api-keys", body, "Authorization", "Bearer "+reader)
+	assert.Equal(t, http.StatusForbidden, rr.Code)
+
+	rr = do(http.MethodPost, "/api-keys", body, "Authorization", "Bearer "+admin)
+	require.Equal(t, http.StatusCreated, rr.Code)
+	var created models.CreatedAPIKey
+	require.NoError(t, json.Unmarshal(rr.Body.Bytes(), &created))
+	require.NotEmpty(t, created.Key)
+
+	rr = do(http.MethodGet, "/api-keys", nil, "Authorization", "Bearer "+admin)
+	require.Equal(t, http.StatusOK, rr.Code)
+	assert.NotContains(t, rr.Body.String(), created.Key)
+	assert.Contains(t, rr.Body.String(), created.Prefix)
+
+	// The key opens routes within its scopes only
+	rr = do(http.MethodGet, "/orders/b563feb7b2b84b6test", nil, middleware.APIKeyHeader, created.Key)
+	assert.Equal(t, http.StatusOK, rr.Code)
+	rr = do(http.MethodPut, "/orders/b563feb7b2b84b6test", []byte(`{}`), middleware.APIKeyHeader, created.Key)
+	assert.Equal(t, http.StatusForbidden, rr.Code)
+	rr = do(http.MethodGet, "/api-keys", nil, middleware.APIKeyHeader, created.Key)
+	assert.Equal(t, http.StatusForbidden, rr.Code)
+
+	rr = do(http.MethodDelete, fmt.Sprintf("/api-keys/%d", created.ID), nil, "Authorization", "Bearer "+admin)
+	assert.Equal(t, http.StatusOK, rr.Code)
+	rr = do(http.MethodDelete, "/api-keys/42", nil, "Authorization", "Bearer "+admin)
+	assert.Equal(t, http.StatusNotFound, rr.Code)
+
+	rr = do(http.MethodGet, "/orders/b563feb7b2b84b6test", nil, middleware.APIKeyHeader, created.Key)
+	assert.Equal(t, http.StatusUnauthorized, rr.Code)
+	assert.Contains(t, rr.Body.String(), "invalid api key")
+}
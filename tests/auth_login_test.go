@@ -0,0 +1,205 @@
+package tests
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	handler "wb-test/internal/handlers"
+	"wb-test/internal/models"
+	"wb-test/pkg/config"
+)
+
+type memoryUserRepo struct {
+	mu    sync.Mutex
+	users map[string]*models.User
+}
+
+func newMemoryUserRepo() *memoryUserRepo {
+	return &memoryUserRepo{users: make(map[string]*models.User)}
+}
+
+func (r *memoryUserRepo) CreateUser(user *models.User) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if _, ok := r.users[user.Username]; ok {
+		return models.ErrUserExists
+	}
+	user.ID = len(r.users) + 1
+	user.CreatedAt = time.Now()
+	stored := *user
+	r.users[user.Username] = &stored
+	return nil
+}
+
+func (r *memoryUserRepo) GetUserByUsername(username string) (*models.User, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	user, ok := r.users[username]
+	if !ok {
+		return nil, models.ErrUserNotFound
+	}
+	found := *user
+	return &found, nil
+}
+
+func (r *memoryUserRepo) GetUserByID(userID int) (*models.User, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	user := r.byID(userID)
+	if user == nil {
+		return nil, models.ErrUserNotFound
+	}
+	found := *user
+	return &found, nil
+}
+
+func (r *memoryUserRepo) SetUserRoles(username string, roles, scopes []string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	user, ok := r.users[username]
+	if !ok {
+		return models.ErrUserNotFound
+	}
+	user.Roles, user.Scopes = roles, scopes
+	return nil
+}
+
+func (r *memoryUserRepo) byID(userID int) *models.User {
+	for _, user := range r.users {
+		if user.ID == userID {
+			return user
+		}
+	}
+	return nil
+}
+
+func (r *memoryUserRepo) RecordLoginFailure(userID, maxAttempts int, lockout time.Duration) (bool, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	user := r.byID(userID)
+	user.FailedAttempts++
+	if user.FailedAttempts < maxAttempts {
+		return false, nil
+	}
+	lockedUntil := time.Now().Add(lockout)
+	user.FailedAttempts, user.LockedUntil = 0, &lockedUntil
+	return true, nil
+}
+
+func (r *memoryUserRepo) RecordLoginSuccess(userID int) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	user := r.byID(userID)
+	user.FailedAttempts, user.LockedUntil = 0, nil
+	return nil
+}
+
+func TestCreateUser(t *testing.T) {
+	_, _, users := newAuthService()
+
+	user, err := users.CreateUser("john_doe", "correct horse", []string{"admin"}, nil)
+	require.NoError(t, err)
+	assert.NotZero(t, user.ID)
+	assert.NotContains(t, user.PasswordHash, "correct horse")
+
+	_, err = users.CreateUser("john_doe", "correct horse", nil, nil)
+	assert.ErrorIs(t, err, models.ErrUserExists)
+
+	_, err = users.CreateUser("jane_doe", "short", nil, nil)
+	assert.ErrorIs(t, err, models.ErrInvalidPassword)
+
+	require.NoError(t, users.SetUserRoles("john_doe", nil, []string{"orders:read"}))
+	assert.ErrorIs(t, users.SetUserRoles("nobody", nil, nil), models.ErrUserNotFound)
+}
+
+func TestLoginIssuesTokensWithRoles(t *testing.T) {
+	service, keys, users := newAuthService()
+	_, err := users.CreateUser("john_doe", "correct horse", []string{"admin"}, []string{"orders:read"})
+	require.NoError(t, err)
+
+	pair, err := service.Login("john_doe", "correct horse")
+	require.NoError(t, err)
+
+	claims, err := keys.ParseJWT(pair.AccessToken)
+	require.NoError(t, err)
+	assert.Equal(t, "john_doe", claims.Username)
+	assert.Equal(t, []string{"admin"}, claims.Roles)
+	assert.Equal(t, []string{"orders:read"}, claims.Scopes)
+
+	_, err = service.Login("john_doe", "wrong password")
+	assert.ErrorIs(t, err, models.ErrInvalidCredentials)
+	_, err = service.Login("nobody", "correct horse")
+	assert.ErrorIs(t, err, models.ErrInvalidCredentials)
+}
+
+func TestLoginLocksAfterRepeatedFailures(t *testing.T) {
+	service, _, users := newAuthService()
+	_, err := users.CreateUser("john_doe", "correct horse", nil, nil)
+	require.NoError(t, err)
+
+	// Successful logins reset the count
+	for i := 0; i < 2; i++ {
+		_, err = service.Login("john_doe", "wrong password")
+		assert.ErrorIs(t, err, models.ErrInvalidCredentials)
+	}
+	_, err = service.Login("john_doe", "correct horse")
+	require.NoError(t, err)
+
+	for i := 0; i < 3; i++ {
+		_, err = service.Login("john_doe", "wrong password")
+		assert.ErrorIs(t, err, models.ErrInvalidCredentials)
+	}
+
+	// Even the right password is refused while locked
+	_, err = service.Login("john_doe", "correct horse")
+	assert.ErrorIs(t, err, models.ErrUserLocked)
+}
+
+func TestConfigRejectsLoginMaxAttemptsBelowOne(t *testing.T) {
+	t.Setenv("DB_DSN", "postgres://localhost/wb_orders")
+	t.Setenv("AUTH_LOGIN_MAX_ATTEMPTS", "0")
+
+	_, err := config.Load()
+	assert.ErrorContains(t, err, "AUTH_LOGIN_MAX_ATTEMPTS")
+}
+
+func TestLoginEndpoint(t *testing.T) {
+	service, keys, users := newAuthService()
+	router := handler.InitRouter(handler.NewHandler(&stubOrderService{orders: map[string]*models.Order{}}, nil, keys, service, nil))
+	_, err := users.CreateUser("john_doe", "correct horse", nil, []string{"orders:read"})
+	require.NoError(t, err)
+
+	login := func(username, password string) *httptest.ResponseRecorder {
+		body, _ := json.Marshal(models.LoginRequest{Username: username, Password: password})
+		rr := httptest.NewRecorder()
+		router.ServeHTTP(rr, httptest.NewRequest(http.MethodPost, "/auth/login", bytes.NewReader(body)))
+		return rr
+	}
+
+	rr := login("john_doe", "correct horse")
+	require.Equal(t, http.StatusOK, rr.Code)
+	var pair models.TokenPair
+	require.NoError(t, json.Unmarshal(rr.Body.Bytes(), &pair))
+
+	// The issued token opens the order API
+	req := httptest.NewRequest(http.MethodGet, "/orders/b563feb7b2b84b6test", nil)
+	req.Header.Set("Authorization", "Bearer "+pair.AccessToken)
+	rr = httptest.NewRecorder()
+	router.ServeHTTP(rr, req)
+	assert.NotEqual(t, http.StatusUnauthorized, rr.Code)
+	assert.NotEqual(t, http.StatusForbidden, rr.Code)
+
+	assert.Equal(t, http.StatusBadRequest, login("john_doe", "").Code)
+	for i := 0; i < 3; i++ {
+		assert.Equal(t, http.StatusUnauthorized, login("john_doe", "wrong password").Code)
+	}
+	assert.Equal(t, http.StatusLocked, login("john_doe", "correct horse").Code)
+}
@@ -0,0 +1,191 @@
+package tests
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	jwtlib "github.com/golang-jwt/jwt/v5"
+	"github.com/gorilla/mux"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"wb-test/internal/handlers/middleware"
+	httputils "wb-test/pkg/utils/http-utils"
+	"wb-test/pkg/utils/jwt"
+)
+
+func newAuthRouter() *mux.Router {
+	router := mux.NewRouter()
+	auth := middleware.NewAuth(jwt.NewDevKeySet(), nil)
+	router.Use(auth.Middleware)
+
+	auth.Public(router.HandleFunc("/live", func(w http.ResponseWriter, r *http.Request) {
+		httputils.WriteResponse(w, http.StatusOK, "ok", nil, nil)
+	}))
+	router.HandleFunc("/me", func(w http.ResponseWriter, r *http.Request) {
+		claims, ok := jwt.GetUserFromContext(r.Context())
+		if !ok {
+			httputils.WriteResponse(w, http.StatusInternalServerError, "no claims", nil, nil)
+			return
+		}
+		httputils.WriteResponse(w, http.StatusOK, "ok", nil, claims)
+	})
+	return router
+}
+
+func TestAuthMiddleware(t *testing.T) {
+	router := newAuthRouter()
+
+	valid, err := jwt.GenerateJWT(123, "john_doe")
+	require.NoError(t, err)
+
+	expired, err := jwtlib.NewWithClaims(jwtlib.SigningMethodHS256, jwt.Claims{
+		UserID: 123,
+		RegisteredClaims: jwtlib.RegisteredClaims{
+			ExpiresAt: jwtlib.NewNumericDate(time.Now().Add(-time.Hour)),
+		},
+	}).SignedString(jwt.DevSecret)
+	require.NoError(t, err)
+
+	tests := []struct {
+		name        string
+		path        string
+		header      string
+		wantStatus  int
+		wantMessage string
+	}{
+		{
+			name:       "public route without token",
+			path:       "/live",
+			wantStatus: http.StatusOK,
+		},
+		{
+			name:       "valid token",
+			path:       "/me",
+			header:     "Bearer " + valid,
+			wantStatus: http.StatusOK,
+		},
+		{
+			name:        "missing token",
+			path:        "/me",
+			wantStatus:  http.StatusUnauthorized,
+			wantMessage: "missing token",
+		},
+		{
+			name:        "expired token",
+			path:        "/me",
+			header:      "Bearer " + expired,
+			wantStatus:  http.StatusUnauthorized,
+			wantMessage: "token expired",
+		},
+		{
+			name:        "invalid token",
+			path:        "/me",
+			header:      "Bearer invalid.token.value",
+			wantStatus:  http.StatusUnauthorized,
+			wantMessage: "invalid token",
+		},
+		{
+			name:        "not a bearer token",
+			path:        "/me",
+			header:      "Basic dXNlcjpwYXNz",
+			wantStatus:  http.StatusUnauthorized,
+			wantMessage: "invalid token",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodGet, tt.path, nil)
+			if tt.header != "" {
+				req.Header.Set("Authorization", tt.header)
+			}
+			rec := httptest.NewRecorder()
+			router.ServeHTTP(rec, req)
+			require.Equal(t, tt.wantStatus, rec.Code, rec.Body.String())
+
+			if tt.wantStatus == http.StatusUnauthorized {
+				var errResp httputils.ErrorResponse
+				require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &errResp))
+				assert.Equal(t, tt.wantMessage, errResp.Message)
+				assert.Contains(t, rec.Header().Get("WWW-Authenticate"), "Bearer")
+			}
+			if tt.path == "/me" && tt.wantStatus == http.StatusOK {
+				var claims jwt.Claims
+				require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &claims))
+				assert.Equal(t, 123, claims.UserID)
+			}
+		})
+	}
+}
+
+func TestAuthorization(t *testing.T) {
+	router := mux.NewRouter()
+	auth := middleware.NewAuth(jwt.NewDevKeySet(), nil)
+	router.Use(auth.Middleware)
+
+	ok := func(w http.ResponseWriter, r *http.Request) {
+		httputils.WriteResponse(w, http.StatusOK, "ok", nil, nil)
+	}
+	auth.RequireScope(router.HandleFunc("/orders", ok).Methods(http.MethodGet), middleware.ScopeOrdersRead)
+	auth.RequireScope(router.HandleFunc("/orders", ok).Methods(http.MethodPut), middleware.ScopeOrdersWrite)
+	auth.RequireRole(router.HandleFunc("/dead-letters", ok).Methods(http.MethodGet), middleware.RoleAdmin)
+
+	token := func(opts ...jwt.Option) string {
+		token, err := jwt.GenerateJWT(1, "user", opts...)
+		require.NoError(t, err)
+		return token
+	}
+	support := token(jwt.WithRoles("support"), jwt.WithScopes(middleware.ScopeOrdersRead))
+	admin := token(jwt.WithRoles(middleware.RoleAdmin))
+	bare := token()
+
+	tests := []struct {
+		name       string
+		method     string
+		path       string
+		token      string
+		wantStatus int
+	}{
+		{name: "support reads orders", method: http.MethodGet, path: "/orders", token: support, wantStatus: http.StatusOK},
+		{name: "support cannot write orders", method: http.MethodPut, path: "/orders", token: support, wantStatus: http.StatusForbidden},
+		{name: "support cannot list dead letters", method: http.MethodGet, path: "/dead-letters", token: support, wantStatus: http.StatusForbidden},
+		{name: "admin writes orders", method: http.MethodPut, path: "/orders", token: admin, wantStatus: http.StatusOK},
+		{name: "admin lists dead letters", method: http.MethodGet, path: "/dead-letters", token: admin, wantStatus: http.StatusOK},
+		{name: "token without scopes", method: http.MethodGet, path: "/orders", token: bare, wantStatus: http.StatusForbidden},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(tt.method, tt.path, nil)
+			req.Header.Set("Authorization", "Bearer "+tt.token)
+			rec := httptest.NewRecorder()
+			router.ServeHTTP(rec, req)
+			require.Equal(t, tt.wantStatus, rec.Code, rec.Body.String())
+
+			if tt.wantStatus == http.StatusForbidden {
+				var errResp httputils.ErrorResponse
+				require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &errResp))
+				assert.Equal(t, "forbidden", errResp.Message)
+			}
+		})
+	}
+}
+
+func TestRefreshKeepsRolesAndScopes(t *testing.T) {
+	service, keys, users := newAuthService()
+	user := newTestUser(t, users, []string{"support"}, []string{middleware.ScopeOrdersRead})
+	pair, err := service.IssueTokens(user.ID, user.Username, user.Roles, user.Scopes)
+	require.NoError(t, err)
+
+	refreshed, err := service.Refresh(pair.RefreshToken)
+	require.NoError(t, err)
+	claims, err := keys.ParseJWT(refreshed.AccessToken)
+	require.NoError(t, err)
+	assert.True(t, claims.HasRole("support"))
+	assert.True(t, claims.HasScope(middleware.ScopeOrdersRead))
+	assert.False(t, claims.HasScope(middleware.ScopeOrdersWrite))
+}
@@ -0,0 +1,269 @@
+package tests
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	handler "wb-test/internal/handlers"
+	"wb-test/internal/models"
+	authservice "wb-test/internal/service/auth"
+	userservice "wb-test/internal/service/user"
+	"wb-test/pkg/config"
+	"wb-test/pkg/utils/jwt"
+)
+
+type memoryTokenStore struct {
+	mu       sync.Mutex
+	sessions map[string]models.RefreshSession
+	used     map[string]bool
+	revoked  map[string]bool
+	denied   map[string]bool
+}
+
+func newMemoryTokenStore() *memoryTokenStore {
+	return &memoryTokenStore{
+		sessions: make(map[string]models.RefreshSession),
+		used:     make(map[string]bool),
+		revoked:  make(map[string]bool),
+		denied:   make(map[string]bool),
+	}
+}
+
+func (s *memoryTokenStore) SaveSession(hash string, session *models.RefreshSession) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.sessions[hash] = *session
+	return nil
+}
+
+func (s *memoryTokenStore) GetSession(hash string) (*models.RefreshSession, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	session, ok := s.sessions[hash]
+	if !ok {
+		return nil, nil
+	}
+	return &session, nil
+}
+
+func (s *memoryTokenStore) MarkUsed(hash string, ttl time.Duration) (bool, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.used[hash] {
+		return false, nil
+	}
+	s.used[hash] = true
+	return true, nil
+}
+
+func (s *memoryTokenStore) RevokeFamily(family string, ttl time.Duration) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.revoked[family] = true
+	return nil
+}
+
+func (s *memoryTokenStore) IsFamilyRevoked(family string) (bool, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.revoked[family], nil
+}
+
+func (s *memoryTokenStore) DenyToken(jti string, ttl time.Duration) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.denied[jti] = true
+	return nil
+}
+
+func (s *memoryTokenStore) IsTokenDenied(jti string) (bool, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.denied[jti], nil
+}
+
+func newAuthService() (*authservice.AuthService, *jwt.KeySet, *userservice.UserService) {
+	keys := jwt.NewDevKeySet()
+	store := newMemoryTokenStore()
+	keys.SetDenylist(store)
+	users := userservice.NewUserService(newMemoryUserRepo(), config.AuthConfig{LoginMaxAttempts: 3, LoginLockout: time.Minute})
+	return authservice.NewAuthService(keys, store, users, 15*time.Minute, 24*time.Hour), keys, users
+}
+
+// newTestUser creates john_doe, tokens are only refreshed for existing users
+func newTestUser(t *testing.T, users *userservice.UserService, roles, scopes []string) *models.User {
+	t.Helper()
+	user, err := users.CreateUser("john_doe", "correct horse", roles, scopes)
+	require.NoError(t, err)
+	return user
+}
+
+func TestRefreshRotatesTokens(t *testing.T) {
+	service, keys, users := newAuthService()
+	user := newTestUser(t, users, nil, []string{"orders:read"})
+
+	pair, err := service.IssueTokens(user.ID, user.Username, user.Roles, user.Scopes)
+	require.NoError(t, err)
+	assert.Equal(t, "Bearer", pair.TokenType)
+	assert.Equal(t, 900, pair.ExpiresIn)
+
+	claims, err := keys.ParseJWT(pair.AccessToken)
+	require.NoError(t, err)
+	assert.NotEmpty(t, claims.ID)
+	assert.Equal(t, []string{"orders:read"}, claims.Scopes)
+	assert.WithinDuration(t, time.Now().Add(15*time.Minute), claims.ExpiresAt.Time, 5*time.Second)
+
+	next, err := service.Refresh(pair.RefreshToken)
+	require.NoError(t, err)
+	assert.NotEqual(t, pair.RefreshToken, next.RefreshToken)
+	assert.NotEqual(t, pair.AccessToken, next.AccessToken)
+
+	claims, err = keys.ParseJWT(next.AccessToken)
+	require.NoError(t, err)
+	assert.Equal(t, user.ID, claims.UserID)
+	assert.Equal(t, []string{"orders:read"}, claims.Scopes)
+
+	_, err = service.Refresh("unknown")
+	assert.ErrorIs(t, err, models.ErrInvalidRefreshToken)
+}
+
+func TestRefreshReuseRevokesFamily(t *testing.T) {
+	service, _, users := newAuthService()
+	user := newTestUser(t, users, nil, nil)
+
+	first, err := service.IssueTokens(user.ID, user.Username, nil, nil)
+	require.NoError(t, err)
+	second, err := service.Refresh(first.RefreshToken)
+	require.NoError(t, err)
+
+	// Replaying the spent token revokes the whole family, the legitimate latest token included
+	_, err = service.Refresh(first.RefreshToken)
+	assert.ErrorIs(t, err, models.ErrRefreshTokenReused)
+	_, err = service.Refresh(second.RefreshToken)
+	assert.ErrorIs(t, err, models.ErrInvalidRefreshToken)
+
+	// Other logins of the same user are untouched
+	other, err := service.IssueTokens(user.ID, user.Username, nil, nil)
+	require.NoError(t, err)
+	_, err = service.Refresh(other.RefreshToken)
+	assert.NoError(t, err)
+}
+
+func TestLogoutRevokesTokens(t *testing.T) {
+	service, keys, users := newAuthService()
+	user := newTestUser(t, users, nil, nil)
+
+	pair, err := service.IssueTokens(user.ID, user.Username, nil, nil)
+	require.NoError(t, err)
+	claims, err := keys.ParseJWT(pair.AccessToken)
+	require.NoError(t, err)
+
+	require.NoError(t, service.Logout(claims, pair.RefreshToken))
+
+	_, err = keys.ParseJWT(pair.AccessToken)
+	assert.ErrorIs(t, err, jwt.ErrRevokedToken)
+	_, err = service.Refresh(pair.RefreshToken)
+	assert.ErrorIs(t, err, models.ErrInvalidRefreshToken)
+}
+
+func TestAuthEndpoints(t *testing.T) {
+	service, keys, users := newAuthService()
+	router := handler.InitRouter(handler.NewHandler(&stubOrderService{orders: map[string]*models.Order{}}, nil, keys, service, nil))
+	user := newTestUser(t, users, nil, nil)
+
+	pair, err := service.IssueTokens(user.ID, user.Username, nil, nil)
+	require.NoError(t, err)
+
+	refresh := func(token string) *httptest.ResponseRecorder {
+		body, _ := json.Marshal(models.RefreshRequest{RefreshToken: token})
+		rr := httptest.NewRecorder()
+		router.ServeHTTP(rr, httptest.NewRequest(http.MethodPost, "/auth/refresh", bytes.NewReader(body)))
+		return rr
+	}
+
+	rr := refresh(pair.RefreshToken)
+	require.Equal(t, http.StatusOK, rr.Code)
+	assert.Equal(t, "no-store", rr.Header().Get("Cache-Control"))
+	var next models.TokenPair
+	require.NoError(t, json.Unmarshal(rr.Body.Bytes(), &next))
+	assert.NotEmpty(t, next.AccessToken)
+
+	rr = refresh(pair.RefreshToken)
+	assert.Equal(t, http.StatusUnauthorized, rr.Code)
+
+	rr = refresh("")
+	assert.Equal(t, http.StatusBadRequest, rr.Code)
+
+	// Logout needs a valid access token and revokes it
+	rr = httptest.NewRecorder()
+	router.ServeHTTP(rr, httptest.NewRequest(http.MethodPost, "/auth/logout", nil))
+	assert.Equal(t, http.StatusUnauthorized, rr.Code)
+
+	fresh, err := service.IssueTokens(user.ID, user.Username, nil, nil)
+	require.NoError(t, err)
+	logout := func() *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodPost, "/auth/logout", nil)
+		req.Header.Set("Authorization", "Bearer "+fresh.AccessToken)
+		rr := httptest.NewRecorder()
+		router.ServeHTTP(rr, req)
+		return rr
+	}
+	assert.Equal(t, http.StatusOK, logout().Code)
+
+	rr = logout()
+	assert.Equal(t, http.StatusUnauthorized, rr.Code)
+	assert.Contains(t, rr.Body.String(), "token revoked")
+}
+
+func TestRefreshPicksUpRoleChanges(t *testing.T) {
+	service, keys, users := newAuthService()
+	user := newTestUser(t, users, []string{"admin"}, []string{"orders:read", "orders:write"})
+
+	pair, err := service.Login("john_doe", "correct horse")
+	require.NoError(t, err)
+
+	// Demoted between login and refresh
+	require.NoError(t, users.SetUserRoles(user.Username, []string{"support"}, []string{"orders:read"}))
+
+	refreshed, err := service.Refresh(pair.RefreshToken)
+	require.NoError(t, err)
+	claims, err := keys.ParseJWT(refreshed.AccessToken)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"support"}, claims.Roles)
+	assert.Equal(t, []string{"orders:read"}, claims.Scopes)
+
+	// The rotated session carries the new roles too
+	refreshed, err = service.Refresh(refreshed.RefreshToken)
+	require.NoError(t, err)
+	claims, err = keys.ParseJWT(refreshed.AccessToken)
+	require.NoError(t, err)
+	assert.False(t, claims.HasRole("admin"))
+}
+
+func TestRefreshRejectsLockedAndUnknownUsers(t *testing.T) {
+	service, _, users := newAuthService()
+	newTestUser(t, users, nil, nil)
+
+	pair, err := service.Login("john_doe", "correct horse")
+	require.NoError(t, err)
+	for i := 0; i < 3; i++ {
+		_, err = service.Login("john_doe", "wrong password")
+		require.ErrorIs(t, err, models.ErrInvalidCredentials)
+	}
+
+	_, err = service.Refresh(pair.RefreshToken)
+	assert.ErrorIs(t, err, models.ErrUserLocked)
+
+	orphan, err := service.IssueTokens(999, "nobody", nil, nil)
+	require.NoError(t, err)
+	_, err = service.Refresh(orphan.RefreshToken)
+	assert.ErrorIs(t, err, models.ErrInvalidRefreshToken)
+}
@@ -0,0 +1,134 @@
+package tests
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gorilla/mux"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"wb-test/internal/handlers/deadletter"
+	"wb-test/pkg/broker"
+)
+
+type stubDeadLetterQueue struct {
+	letters   map[uint64]broker.DeadLetter
+	lastLimit int
+	replayed  []string
+	err       error
+}
+
+func (q *stubDeadLetterQueue) List(_ context.Context, limit int) ([]broker.DeadLetter, error) {
+	q.lastLimit = limit
+	if q.err != nil {
+		return nil, q.err
+	}
+	letters := make([]broker.DeadLetter, 0, len(q.letters))
+	for _, letter := range q.letters {
+		letters = append(letters, letter)
+	}
+	return letters, nil
+}
+
+func (q *stubDeadLetterQueue) Replay(_ context.Context, seq uint64, fallbackSubject string) error {
+	letter, ok := q.letters[seq]
+	if !ok {
+		return fmt.Errorf("%w: %d", broker.ErrDeadLetterNotFound, seq)
+	}
+	subject := letter.OriginalSubject
+	if subject == "" {
+		subject = fallbackSubject
+	}
+	q.replayed = append(q.replayed, subject)
+	delete(q.letters, seq)
+	return nil
+}
+
+func newDeadLetterRouter(queue *stubDeadLetterQueue) *mux.Router {
+	h := deadletter.NewHandler(queue, "orders.new")
+	router := mux.NewRouter()
+	router.HandleFunc("/dead-letters", h.List).Methods(http.MethodGet)
+	router.HandleFunc("/dead-letters/{sequence}/replay", h.Replay).Methods(http.MethodPost)
+	return router
+}
+
+func TestListDeadLettersHandler(t *testing.T) {
+	tests := []struct {
+		name       string
+		query      string
+		wantStatus int
+		wantLimit  int
+	}{
+		{name: "default limit", query: "", wantStatus: http.StatusOK, wantLimit: 50},
+		{name: "explicit limit", query: "?limit=10", wantStatus: http.StatusOK, wantLimit: 10},
+		{name: "max limit", query: "?limit=1000", wantStatus: http.StatusOK, wantLimit: 1000},
+		{name: "zero limit", query: "?limit=0", wantStatus: http.StatusBadRequest},
+		{name: "negative limit", query: "?limit=-1", wantStatus: http.StatusBadRequest},
+		{name: "limit over max", query: "?limit=1001", wantStatus: http.StatusBadRequest},
+		{name: "non-numeric limit", query: "?limit=ten", wantStatus: http.StatusBadRequest},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			queue := &stubDeadLetterQueue{letters: map[uint64]broker.DeadLetter{
+				7: {Sequence: 7, OriginalSubject: "orders.new", Error: "boom"},
+			}}
+
+			rec := httptest.NewRecorder()
+			newDeadLetterRouter(queue).ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/dead-letters"+tt.query, nil))
+			require.Equal(t, tt.wantStatus, rec.Code, rec.Body.String())
+			if tt.wantStatus != http.StatusOK {
+				assert.Zero(t, queue.lastLimit, "queue must not be read with an invalid limit")
+				return
+			}
+
+			assert.Equal(t, tt.wantLimit, queue.lastLimit)
+			var letters []broker.DeadLetter
+			require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &letters))
+			require.Len(t, letters, 1)
+			assert.Equal(t, uint64(7), letters[0].Sequence)
+		})
+	}
+}
+
+func TestListDeadLettersHandlerQueueError(t *testing.T) {
+	queue := &stubDeadLetterQueue{err: errors.New("dead-letter queue requires JetStream")}
+
+	rec := httptest.NewRecorder()
+	newDeadLetterRouter(queue).ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/dead-letters", nil))
+	assert.Equal(t, http.StatusInternalServerError, rec.Code)
+}
+
+func TestReplayDeadLetterHandler(t *testing.T) {
+	tests := []struct {
+		name         string
+		sequence     string
+		wantStatus   int
+		wantReplayed []string
+	}{
+		{name: "replays to original subject", sequence: "7", wantStatus: http.StatusOK, wantReplayed: []string{"orders.updated"}},
+		{name: "replays without original subject to fallback", sequence: "8", wantStatus: http.StatusOK, wantReplayed: []string{"orders.new"}},
+		{name: "unknown sequence", sequence: "9", wantStatus: http.StatusNotFound},
+		{name: "invalid sequence", sequence: "abc", wantStatus: http.StatusBadRequest},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			queue := &stubDeadLetterQueue{letters: map[uint64]broker.DeadLetter{
+				7: {Sequence: 7, OriginalSubject: "orders.updated"},
+				8: {Sequence: 8},
+			}}
+
+			rec := httptest.NewRecorder()
+			newDeadLetterRouter(queue).ServeHTTP(rec, httptest.NewRequest(http.MethodPost, "/dead-letters/"+tt.sequence+"/replay", nil))
+			require.Equal(t, tt.wantStatus, rec.Code, rec.Body.String())
+			assert.Equal(t, tt.wantReplayed, queue.replayed)
+		})
+	}
+}
@@ -0,0 +1,115 @@
+package tests
+
+import (
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/pem"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	jwtlib "github.com/golang-jwt/jwt/v5"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	handler "wb-test/internal/handlers"
+	"wb-test/internal/models"
+	"wb-test/pkg/config"
+	"wb-test/pkg/utils/jwt"
+)
+
+// writePrivateKey stores key as a PKCS#8 PEM file and returns its kid:path entry
+func writePrivateKey(t *testing.T, kid string, key any) string {
+	t.Helper()
+	der, err := x509.MarshalPKCS8PrivateKey(key)
+	require.NoError(t, err)
+
+	path := filepath.Join(t.TempDir(), kid+".pem")
+	require.NoError(t, os.WriteFile(path, pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: der}), 0o600))
+	return kid + ":" + path
+}
+
+func TestJWKSVerifiesAsymmetricTokens(t *testing.T) {
+	rsaKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	require.NoError(t, err)
+	ecKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+	_, edKey, err := ed25519.GenerateKey(rand.Reader)
+	require.NoError(t, err)
+
+	cfg := config.AuthConfig{
+		Keys: []string{"hmac:" + strings.Repeat("s", 32)},
+		PrivateKeyFiles: []string{
+			writePrivateKey(t, "rsa", rsaKey),
+			writePrivateKey(t, "ec", ecKey),
+			writePrivateKey(t, "ed", edKey),
+		},
+	}
+
+	tests := []struct {
+		kid string
+		alg string
+	}{
+		{kid: "rsa", alg: "RS256"},
+		{kid: "ec", alg: "ES256"},
+		{kid: "ed", alg: "EdDSA"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.alg, func(t *testing.T) {
+			cfg := cfg
+			cfg.SigningKeyID = tt.kid
+			keys, err := jwt.LoadKeySet(cfg)
+			require.NoError(t, err)
+
+			token, err := keys.GenerateJWT(42, "svc")
+			require.NoError(t, err)
+			parsed, _, err := jwtlib.NewParser().ParseUnverified(token, &jwt.Claims{})
+			require.NoError(t, err)
+			assert.Equal(t, tt.alg, parsed.Method.Alg())
+			assert.Equal(t, tt.kid, parsed.Header["kid"])
+
+			// Fetch the published keys like another service would
+			router := handler.InitRouter(handler.NewHandler(&stubOrderService{orders: map[string]*models.Order{}}, nil, keys, nil, nil))
+			rec := httptest.NewRecorder()
+			router.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/.well-known/jwks.json", nil))
+			require.Equal(t, http.StatusOK, rec.Code)
+			assert.NotContains(t, rec.Body.String(), `"hmac"`, "HMAC secrets must not be published")
+
+			published, err := jwt.ParseJWKS(rec.Body.Bytes())
+			require.NoError(t, err)
+			claims, err := published.ParseJWT(token)
+			require.NoError(t, err)
+			assert.Equal(t, 42, claims.UserID)
+
+			// The published set only verifies
+			_, err = published.GenerateJWT(42, "svc")
+			assert.Error(t, err)
+		})
+	}
+}
+
+func TestKeySetRejectsAlgorithmConfusion(t *testing.T) {
+	rsaKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	require.NoError(t, err)
+	keys, err := jwt.LoadKeySet(config.AuthConfig{PrivateKeyFiles: []string{writePrivateKey(t, "rsa", rsaKey)}})
+	require.NoError(t, err)
+
+	// An HS256 token keyed with the public RSA key must not pass as the RSA key
+	publicDER, err := x509.MarshalPKIXPublicKey(&rsaKey.PublicKey)
+	require.NoError(t, err)
+	forged := jwtlib.NewWithClaims(jwtlib.SigningMethodHS256, jwt.Claims{UserID: 1})
+	forged.Header["kid"] = "rsa"
+	token, err := forged.SignedString(pem.EncodeToMemory(&pem.Block{Type: "PUBLIC KEY", Bytes: publicDER}))
+	require.NoError(t, err)
+
+	_, err = keys.ParseJWT(token)
+	assert.ErrorIs(t, err, jwt.ErrInvalidToken)
+}
@@ -0,0 +1,120 @@
+package tests
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"wb-test/pkg/config"
+	"wb-test/pkg/utils/jwt"
+)
+
+func TestLoadKeySet(t *testing.T) {
+	secret := strings.Repeat("a", 32)
+	keyFile := filepath.Join(t.TempDir(), "jwt.key")
+	require.NoError(t, os.WriteFile(keyFile, []byte(strings.Repeat("f", 32)+"\n"), 0o600))
+
+	tests := []struct {
+		name    string
+		cfg     config.AuthConfig
+		wantErr bool
+	}{
+		{
+			name:    "no keys outside dev mode",
+			cfg:     config.AuthConfig{},
+			wantErr: true,
+		},
+		{
+			name: "no keys in dev mode",
+			cfg:  config.AuthConfig{DevMode: true},
+		},
+		{
+			name: "keys from env",
+			cfg:  config.AuthConfig{Keys: []string{"new:" + secret, "old:" + strings.Repeat("b", 32)}},
+		},
+		{
+			name: "key from file",
+			cfg:  config.AuthConfig{KeyFiles: []string{"file:" + keyFile}},
+		},
+		{
+			name:    "short secret",
+			cfg:     config.AuthConfig{Keys: []string{"new:short"}},
+			wantErr: true,
+		},
+		{
+			name:    "missing kid",
+			cfg:     config.AuthConfig{Keys: []string{secret}},
+			wantErr: true,
+		},
+		{
+			name:    "duplicate kid",
+			cfg:     config.AuthConfig{Keys: []string{"new:" + secret}, KeyFiles: []string{"new:" + keyFile}},
+			wantErr: true,
+		},
+		{
+			name:    "unknown signing key",
+			cfg:     config.AuthConfig{Keys: []string{"new:" + secret}, SigningKeyID: "other"},
+			wantErr: true,
+		},
+		{
+			name:    "missing key file",
+			cfg:     config.AuthConfig{KeyFiles: []string{"file:" + keyFile + ".missing"}},
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			keys, err := jwt.LoadKeySet(tt.cfg)
+			if tt.wantErr {
+				assert.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+
+			token, err := keys.GenerateJWT(1, "user")
+			require.NoError(t, err)
+			_, err = keys.ParseJWT(token)
+			assert.NoError(t, err)
+		})
+	}
+}
+
+func TestKeySetRotation(t *testing.T) {
+	oldSecret := []byte(strings.Repeat("o", 32))
+	newSecret := []byte(strings.Repeat("n", 32))
+
+	before, err := jwt.NewKeySet("old", jwt.HMACKey("old", oldSecret))
+	require.NoError(t, err)
+	oldToken, err := before.GenerateJWT(1, "user")
+	require.NoError(t, err)
+
+	// The new key signs, the old one still verifies
+	during, err := jwt.NewKeySet("new", jwt.HMACKey("new", newSecret), jwt.HMACKey("old", oldSecret))
+	require.NoError(t, err)
+	newToken, err := during.GenerateJWT(1, "user")
+	require.NoError(t, err)
+
+	_, err = during.ParseJWT(oldToken)
+	assert.NoError(t, err)
+	_, err = during.ParseJWT(newToken)
+	assert.NoError(t, err)
+
+	// Once the old key is dropped its tokens are rejected
+	after, err := jwt.NewKeySet("new", jwt.HMACKey("new", newSecret))
+	require.NoError(t, err)
+	_, err = after.ParseJWT(oldToken)
+	assert.ErrorIs(t, err, jwt.ErrInvalidToken)
+	_, err = after.ParseJWT(newToken)
+	assert.NoError(t, err)
+
+	// Tokens of the development key set do not verify
+	devToken, err := jwt.GenerateJWT(1, "user")
+	require.NoError(t, err)
+	_, err = after.ParseJWT(devToken)
+	assert.ErrorIs(t, err, jwt.ErrInvalidToken)
+}
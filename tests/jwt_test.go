@@ -285,10 +285,16 @@ func TestGetUserFromContext(t *testing.T) {
 	}{
 		{
 			name:       "user in context",
-			ctx:        context.WithValue(context.Background(), "user", testClaims),
+			ctx:        jwt.ContextWithUser(context.Background(), testClaims),
 			wantClaims: testClaims,
 			wantOK:     true,
 		},
+		{
+			name:       "untyped user key is ignored",
+			ctx:        context.WithValue(context.Background(), "user", testClaims),
+			wantClaims: nil,
+			wantOK:     false,
+		},
 		{
 			name:       "no user in context",
 			ctx:        context.Background(),
@@ -312,84 +318,6 @@ func TestGetUserFromContext(t *testing.T) {
 	}
 }
 
-func TestRefreshToken(t *testing.T) {
-	// Generate original token
-	userID := 123
-	username := "test_user"
-	originalToken, err := jwt.GenerateJWT(userID, username)
-	require.NoError(t, err)
-
-	// Parse original token to get original expiration
-	originalClaims, err := jwt.ParseJWT(originalToken)
-	require.NoError(t, err)
-
-	tests := []struct {
-		name    string
-		token   string
-		wantErr bool
-	}{
-		{
-			name:    "valid token refresh",
-			token:   originalToken,
-			wantErr: false,
-		},
-		{
-			name:    "invalid token",
-			token:   "invalid.token",
-			wantErr: true,
-		},
-		{
-			name:    "empty token",
-			token:   "",
-			wantErr: true,
-		},
-	}
-
-	for _, tt := range tests {
-		t.Run(tt.name, func(t *testing.T) {
-			// Add a longer delay to ensure different timestamps
-			time.Sleep(100 * time.Millisecond)
-
-			refreshedToken, err := jwt.RefreshToken(tt.token)
-			if tt.wantErr {
-				assert.Error(t, err)
-				assert.Empty(t, refreshedToken)
-				return
-			}
-
-			require.NoError(t, err)
-			assert.NotEmpty(t, refreshedToken)
-
-			// Parse refreshed token
-			refreshedClaims, err := jwt.ParseJWT(refreshedToken)
-			require.NoError(t, err)
-
-			// Check that user data is preserved
-			assert.Equal(t, originalClaims.UserID, refreshedClaims.UserID)
-			assert.Equal(t, originalClaims.Username, refreshedClaims.Username)
-
-			// Check that expiration time is extended (should be later than original)
-			expirationTimeDiff := refreshedClaims.ExpiresAt.Time.Sub(originalClaims.ExpiresAt.Time)
-			t.Logf("Expiration time difference: %v", expirationTimeDiff)
-			assert.True(t, refreshedClaims.ExpiresAt.Time.After(originalClaims.ExpiresAt.Time),
-				"Refreshed expiration time should be after original expiration time")
-
-			// Check that issued time is at least the same or later (JWT library might use same timestamp)
-			issuedTimeDiff := refreshedClaims.IssuedAt.Time.Sub(originalClaims.IssuedAt.Time)
-			t.Logf("Issued time difference: %v", issuedTimeDiff)
-			assert.True(t, !refreshedClaims.IssuedAt.Time.Before(originalClaims.IssuedAt.Time),
-				"Refreshed issued time should not be before original issued time")
-
-			// Verify that both tokens are valid
-			assert.NoError(t, jwt.ValidateToken(originalToken))
-			assert.NoError(t, jwt.ValidateToken(refreshedToken))
-
-			// Verify the tokens are different (due to different timestamps)
-			assert.NotEqual(t, originalToken, refreshedToken)
-		})
-	}
-}
-
 func TestJWTClaimsStructure(t *testing.T) {
 	// Test that claims structure works correctly
 	userID := 456
@@ -0,0 +1,121 @@
+package tests
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	ordercache "wb-test/internal/cache/order"
+	"wb-test/internal/models"
+)
+
+type memoryOrderCache struct {
+	orders  map[string]*models.Order
+	indexes map[string][]string
+	gets    int
+}
+
+func newMemoryOrderCache() *memoryOrderCache {
+	return &memoryOrderCache{orders: make(map[string]*models.Order), indexes: make(map[string][]string)}
+}
+
+func (c *memoryOrderCache) GetOrder(_ context.Context, orderUID string) (*models.Order, error) {
+	c.gets++
+	return c.orders[orderUID], nil
+}
+
+func (c *memoryOrderCache) SetOrder(_ context.Context, orderUID string, order *models.Order) error {
+	c.orders[orderUID] = order
+	return nil
+}
+
+func (c *memoryOrderCache) SetOrders(_ context.Context, orders []*models.Order) error {
+	for _, order := range orders {
+		c.orders[order.OrderUID] = order
+	}
+	return nil
+}
+
+func (c *memoryOrderCache) DeleteOrder(_ context.Context, orderUID string) error {
+	delete(c.orders, orderUID)
+	return nil
+}
+
+func (c *memoryOrderCache) GetIndexedOrders(_ context.Context, lookup models.OrderLookup, value string) ([]*models.Order, bool, error) {
+	uids, ok := c.indexes[string(lookup)+":"+value]
+	if !ok {
+		return nil, false, nil
+	}
+	orders := make([]*models.Order, 0, len(uids))
+	for _, uid := range uids {
+		order, ok := c.orders[uid]
+		if !ok || order.LookupValue(lookup) != value {
+			return nil, false, nil
+		}
+		orders = append(orders, order)
+	}
+	return orders, true, nil
+}
+
+func (c *memoryOrderCache) SetIndexedOrders(_ context.Context, lookup models.OrderLookup, value string, orders []*models.Order) error {
+	if len(orders) == 0 {
+		return nil
+	}
+	uids := make([]string, len(orders))
+	for i, order := range orders {
+		c.orders[order.OrderUID] = order
+		uids[i] = order.OrderUID
+	}
+	c.indexes[string(lookup)+":"+value] = uids
+	return nil
+}
+
+func (c *memoryOrderCache) DeleteOrderIndexes(_ context.Context, orders []*models.Order) error {
+	for _, order := range orders {
+		for _, lookup := range models.OrderLookups {
+			delete(c.indexes, string(lookup)+":"+order.LookupValue(lookup))
+		}
+	}
+	return nil
+}
+
+func TestLRUOrderCache(t *testing.T) {
+	next := newMemoryOrderCache()
+	cache := ordercache.NewLRUOrderCache(next, 2, time.Minute)
+
+	for _, uid := range []string{"a", "b", "c"} {
+		require.NoError(t, cache.SetOrder(context.Background(), uid, &models.Order{OrderUID: uid}))
+	}
+
+	// "a" was evicted from the local tier and is served by the next tier
+	order, err := cache.GetOrder(context.Background(), "a")
+	require.NoError(t, err)
+	require.NotNil(t, order)
+	assert.Equal(t, 1, next.gets)
+
+	// "a" is local again now, a second read must not reach the next tier
+	_, err = cache.GetOrder(context.Background(), "a")
+	require.NoError(t, err)
+	assert.Equal(t, 1, next.gets)
+
+	// Deleting invalidates both tiers
+	require.NoError(t, cache.DeleteOrder(context.Background(), "a"))
+	order, err = cache.GetOrder(context.Background(), "a")
+	require.NoError(t, err)
+	assert.Nil(t, order)
+}
+
+func TestLRUOrderCacheTTL(t *testing.T) {
+	next := newMemoryOrderCache()
+	cache := ordercache.NewLRUOrderCache(next, 10, 10*time.Millisecond)
+
+	require.NoError(t, cache.SetOrder(context.Background(), "a", &models.Order{OrderUID: "a"}))
+	time.Sleep(20 * time.Millisecond)
+
+	_, err := cache.GetOrder(context.Background(), "a")
+	require.NoError(t, err)
+	assert.Equal(t, 1, next.gets, "expired entry should fall through to the next tier")
+}
@@ -0,0 +1,71 @@
+package tests
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	ordercache "wb-test/internal/cache/order"
+	handler "wb-test/internal/handlers"
+	"wb-test/internal/models"
+	"wb-test/pkg/metrics"
+	"wb-test/pkg/utils/jwt"
+)
+
+func TestMetricsEndpoint(t *testing.T) {
+	router := handler.InitRouter(handler.NewHandler(&stubOrderService{orders: map[string]*models.Order{}}, nil, jwt.NewDevKeySet(), nil, nil))
+
+	// Rejected requests are recorded under their route template
+	rr := httptest.NewRecorder()
+	router.ServeHTTP(rr, httptest.NewRequest(http.MethodGet, "/orders/b563feb7b2b84b6test", nil))
+	require.Equal(t, http.StatusUnauthorized, rr.Code)
+
+	// Requests no route matches are recorded as unmatched
+	rr = httptest.NewRecorder()
+	router.ServeHTTP(rr, httptest.NewRequest(http.MethodGet, "/no-such-page", nil))
+	require.Equal(t, http.StatusNotFound, rr.Code)
+	rr = httptest.NewRecorder()
+	router.ServeHTTP(rr, httptest.NewRequest(http.MethodDelete, "/live", nil))
+	require.Equal(t, http.StatusMethodNotAllowed, rr.Code)
+
+	// Metrics are not served on the API port
+	rr = httptest.NewRecorder()
+	router.ServeHTTP(rr, httptest.NewRequest(http.MethodGet, "/metrics", nil))
+	require.Equal(t, http.StatusNotFound, rr.Code)
+
+	// They are scraped from the metrics router without a token
+	rr = httptest.NewRecorder()
+	handler.InitMetricsRouter().ServeHTTP(rr, httptest.NewRequest(http.MethodGet, "/metrics", nil))
+	require.Equal(t, http.StatusOK, rr.Code)
+
+	body, err := io.ReadAll(rr.Body)
+	require.NoError(t, err)
+	assert.Contains(t, string(body), `wb_http_request_duration_seconds_count{method="GET",route="/orders/{order_uid}",status="401"}`)
+	assert.Contains(t, string(body), `wb_http_request_duration_seconds_count{method="GET",route="unmatched",status="404"}`)
+	assert.Contains(t, string(body), `wb_http_request_duration_seconds_count{method="DELETE",route="unmatched",status="405"}`)
+	assert.NotContains(t, string(body), "b563feb7b2b84b6test")
+	assert.NotContains(t, string(body), "no-such-page")
+}
+
+func TestLRUCacheMetrics(t *testing.T) {
+	hits := testutil.ToFloat64(metrics.CacheRequests.WithLabelValues("lru", metrics.CacheHit))
+	misses := testutil.ToFloat64(metrics.CacheRequests.WithLabelValues("lru", metrics.CacheMiss))
+
+	cache := ordercache.NewLRUOrderCache(newMemoryOrderCache(), 10, time.Minute)
+	require.NoError(t, cache.SetOrder(context.Background(), "b563feb7b2b84b6test", &models.Order{OrderUID: "b563feb7b2b84b6test"}))
+
+	_, err := cache.GetOrder(context.Background(), "b563feb7b2b84b6test")
+	require.NoError(t, err)
+	_, err = cache.GetOrder(context.Background(), "unknown")
+	require.NoError(t, err)
+
+	assert.Equal(t, hits+1, testutil.ToFloat64(metrics.CacheRequests.WithLabelValues("lru", metrics.CacheHit)))
+	assert.Equal(t, misses+1, testutil.ToFloat64(metrics.CacheRequests.WithLabelValues("lru", metrics.CacheMiss)))
+}
@@ -0,0 +1,162 @@
+package tests
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gorilla/mux"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"wb-test/internal/handlers/order"
+	"wb-test/internal/models"
+	httputils "wb-test/pkg/utils/http-utils"
+)
+
+type stubOrderService struct {
+	orders     map[string]*models.Order
+	lastFilter models.OrderFilter
+}
+
+func (s *stubOrderService) GetOrderWithSource(_ context.Context, orderUID string) (*models.Order, models.ReadSource, error) {
+	if o, ok := s.orders[orderUID]; ok {
+		return o, models.ReadSourceCache, nil
+	}
+	return nil, "", models.ErrOrderNotFound
+}
+
+func (s *stubOrderService) UpdateOrder(_ context.Context, order *models.Order) error {
+	if current, ok := s.orders[order.OrderUID]; ok && order.Version != 0 && order.Version != current.Version {
+		return models.ErrOrderVersionMismatch
+	}
+	order.Version++
+	s.orders[order.OrderUID] = order
+	return nil
+}
+
+func (s *stubOrderService) GetOrderHistory(_ context.Context, orderUID string, from, to int) (*models.OrderHistory, error) {
+	if _, ok := s.orders[orderUID]; !ok {
+		return nil, models.ErrOrderNotFound
+	}
+	return &models.OrderHistory{OrderUID: orderUID}, nil
+}
+
+func (s *stubOrderService) ListOrders(_ context.Context, filter models.OrderFilter) (*models.OrderPage, error) {
+	s.lastFilter = filter
+	return &models.OrderPage{Orders: []*models.Order{}}, nil
+}
+
+func (s *stubOrderService) LookupOrders(_ context.Context, lookup models.OrderLookup, value string) ([]*models.Order, error) {
+	var orders []*models.Order
+	for _, o := range s.orders {
+		if o.LookupValue(lookup) == value {
+			orders = append(orders, o)
+		}
+	}
+	return orders, nil
+}
+
+func TestGetOrderHandler(t *testing.T) {
+	service := &stubOrderService{orders: map[string]*models.Order{
+		"b563feb7b2b84b6test": {OrderUID: "b563feb7b2b84b6test", TrackNumber: "WBILMTESTTRACK"},
+	}}
+	router := mux.NewRouter()
+	router.HandleFunc("/orders/{order_uid}", order.NewHandler(service).GetOrder).Methods(http.MethodGet)
+
+	tests := []struct {
+		name       string
+		orderUID   string
+		wantStatus int
+	}{
+		{
+			name:       "existing order",
+			orderUID:   "b563feb7b2b84b6test",
+			wantStatus: http.StatusOK,
+		},
+		{
+			name:       "unknown order",
+			orderUID:   "missing",
+			wantStatus: http.StatusNotFound,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rec := httptest.NewRecorder()
+			router.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/orders/"+tt.orderUID, nil))
+			require.Equal(t, tt.wantStatus, rec.Code)
+
+			if tt.wantStatus == http.StatusOK {
+				var got models.Order
+				require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &got))
+				assert.Equal(t, tt.orderUID, got.OrderUID)
+				assert.Equal(t, "cache", rec.Header().Get(order.SourceHeader))
+				return
+			}
+
+			var errResp httputils.ErrorResponse
+			require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &errResp))
+			assert.Equal(t, tt.wantStatus, errResp.Status)
+		})
+	}
+}
+
+func TestUpdateOrderHandler(t *testing.T) {
+	sample := loadSampleOrder(t)
+	stored := *sample
+	stored.Version = 3
+
+	service := &stubOrderService{orders: map[string]*models.Order{stored.OrderUID: &stored}}
+	router := mux.NewRouter()
+	router.HandleFunc("/orders/{order_uid}", order.NewHandler(service).UpdateOrder).Methods(http.MethodPut)
+
+	tests := []struct {
+		name       string
+		path       string
+		mutate     func(o *models.Order)
+		wantStatus int
+	}{
+		{
+			name:       "valid update",
+			path:       sample.OrderUID,
+			mutate:     func(o *models.Order) { o.Version = 3 },
+			wantStatus: http.StatusOK,
+		},
+		{
+			name:       "stale version",
+			path:       sample.OrderUID,
+			mutate:     func(o *models.Order) { o.Version = 1 },
+			wantStatus: http.StatusConflict,
+		},
+		{
+			name:       "invalid order",
+			path:       sample.OrderUID,
+			mutate:     func(o *models.Order) { o.Payment.Currency = "" },
+			wantStatus: http.StatusUnprocessableEntity,
+		},
+		{
+			name:       "uid does not match path",
+			path:       "other",
+			mutate:     func(o *models.Order) {},
+			wantStatus: http.StatusBadRequest,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			body := *sample
+			body.Items = append([]models.Item(nil), sample.Items...)
+			tt.mutate(&body)
+			data, err := json.Marshal(body)
+			require.NoError(t, err)
+
+			rec := httptest.NewRecorder()
+			router.ServeHTTP(rec, httptest.NewRequest(http.MethodPut, "/orders/"+tt.path, bytes.NewReader(data)))
+			assert.Equal(t, tt.wantStatus, rec.Code, rec.Body.String())
+		})
+	}
+}
@@ -0,0 +1,26 @@
+package tests
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestOrderContentHash(t *testing.T) {
+	order := loadSampleOrder(t)
+	redelivered := loadSampleOrder(t)
+
+	hash, err := order.ContentHash()
+	require.NoError(t, err)
+	assert.Len(t, hash, 64)
+
+	sameHash, err := redelivered.ContentHash()
+	require.NoError(t, err)
+	assert.Equal(t, hash, sameHash, "redelivered order must hash the same")
+
+	redelivered.Delivery.City = "Haifa"
+	otherHash, err := redelivered.ContentHash()
+	require.NoError(t, err)
+	assert.NotEqual(t, hash, otherHash, "changed order must hash differently")
+}
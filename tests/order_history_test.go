@@ -0,0 +1,102 @@
+package tests
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gorilla/mux"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"wb-test/internal/handlers/order"
+	"wb-test/internal/models"
+	orderservice "wb-test/internal/service/order"
+	"wb-test/pkg/utils"
+)
+
+type historyOrderRepo struct {
+	slowOrderRepo
+	versions []models.OrderVersion
+}
+
+func (r *historyOrderRepo) GetOrderHistory(_ context.Context, orderUID string) ([]models.OrderVersion, error) {
+	return r.versions, nil
+}
+
+func TestGetOrderHistoryDiff(t *testing.T) {
+	v1 := *loadSampleOrder(t)
+	v1.Version = 1
+	v2 := v1
+	v2.Version = 2
+	v2.Delivery.City = "Moscow"
+	v2.Items = append([]models.Item(nil), v1.Items...)
+	v2.Items[0].Price = 999
+
+	repo := &historyOrderRepo{versions: []models.OrderVersion{
+		{Version: 1, Source: models.SourceCreate, Order: v1},
+		{Version: 2, Source: models.SourceUpdate, Order: v2},
+	}}
+	service := orderservice.NewOrderService(repo, &syncOrderCache{cache: newMemoryOrderCache()}, utils.Backoff{})
+
+	history, err := service.GetOrderHistory(context.Background(), v1.OrderUID, 1, 2)
+	require.NoError(t, err)
+	require.Len(t, history.Versions, 2)
+	require.NotNil(t, history.Diff)
+	assert.Equal(t, []models.FieldChange{
+		{Field: "delivery.city", From: v1.Delivery.City, To: "Moscow"},
+		{Field: "items[0].price", From: float64(v1.Items[0].Price), To: float64(999)},
+	}, history.Diff.Changes)
+
+	_, err = service.GetOrderHistory(context.Background(), v1.OrderUID, 1, 3)
+	assert.ErrorIs(t, err, models.ErrOrderVersionNotFound)
+}
+
+func TestGetOrderHistoryHandler(t *testing.T) {
+	service := &stubOrderService{orders: map[string]*models.Order{
+		"b563feb7b2b84b6test": {OrderUID: "b563feb7b2b84b6test"},
+	}}
+	router := mux.NewRouter()
+	router.HandleFunc("/orders/{order_uid}/history", order.NewHandler(service).GetOrderHistory).Methods(http.MethodGet)
+
+	tests := []struct {
+		name       string
+		target     string
+		wantStatus int
+	}{
+		{
+			name:       "list versions",
+			target:     "/orders/b563feb7b2b84b6test/history",
+			wantStatus: http.StatusOK,
+		},
+		{
+			name:       "diff versions",
+			target:     "/orders/b563feb7b2b84b6test/history?from=1&to=2",
+			wantStatus: http.StatusOK,
+		},
+		{
+			name:       "only one version given",
+			target:     "/orders/b563feb7b2b84b6test/history?from=1",
+			wantStatus: http.StatusBadRequest,
+		},
+		{
+			name:       "invalid version",
+			target:     "/orders/b563feb7b2b84b6test/history?from=a&to=2",
+			wantStatus: http.StatusBadRequest,
+		},
+		{
+			name:       "unknown order",
+			target:     "/orders/missing/history",
+			wantStatus: http.StatusNotFound,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rec := httptest.NewRecorder()
+			router.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, tt.target, nil))
+			assert.Equal(t, tt.wantStatus, rec.Code, rec.Body.String())
+		})
+	}
+}
@@ -0,0 +1,154 @@
+package tests
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gorilla/mux"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"wb-test/internal/handlers/order"
+	"wb-test/internal/models"
+	orderservice "wb-test/internal/service/order"
+	"wb-test/pkg/utils"
+)
+
+type listOrderRepo struct {
+	slowOrderRepo
+	orders  []*models.Order
+	filters []models.OrderFilter
+}
+
+// ListOrders mimics keyset pagination over orders sorted newest first
+func (r *listOrderRepo) ListOrders(_ context.Context, filter models.OrderFilter) ([]*models.Order, error) {
+	r.filters = append(r.filters, filter)
+	var page []*models.Order
+	for _, o := range r.orders {
+		if filter.After != nil && !o.DateCreated.Before(filter.After.DateCreated) {
+			continue
+		}
+		if len(page) == filter.Limit {
+			break
+		}
+		page = append(page, o)
+	}
+	return page, nil
+}
+
+func TestListOrdersPaginates(t *testing.T) {
+	start := time.Date(2021, 11, 26, 6, 22, 19, 0, time.UTC)
+	repo := &listOrderRepo{}
+	for i := range 5 {
+		repo.orders = append(repo.orders, &models.Order{
+			OrderUID:    string(rune('e' - i)),
+			DateCreated: start.Add(-time.Duration(i) * time.Hour),
+		})
+	}
+	service := orderservice.NewOrderService(repo, &syncOrderCache{cache: newMemoryOrderCache()}, utils.Backoff{})
+
+	var seen []string
+	filter := models.OrderFilter{Limit: 2}
+	for pages := 0; ; pages++ {
+		require.Less(t, pages, 5, "pagination does not terminate")
+
+		page, err := service.ListOrders(context.Background(), filter)
+		require.NoError(t, err)
+		assert.LessOrEqual(t, len(page.Orders), 2)
+		for _, o := range page.Orders {
+			seen = append(seen, o.OrderUID)
+		}
+		if page.NextCursor == "" {
+			break
+		}
+
+		cursor, err := models.DecodeOrderCursor(page.NextCursor)
+		require.NoError(t, err)
+		filter.After = cursor
+	}
+
+	assert.Equal(t, []string{"e", "d", "c", "b", "a"}, seen)
+	assert.Equal(t, 3, repo.filters[0].Limit, "one extra order is fetched to detect the next page")
+}
+
+func TestListOrdersDefaultsMissingLimit(t *testing.T) {
+	repo := &listOrderRepo{}
+	for i := range 25 {
+		repo.orders = append(repo.orders, &models.Order{OrderUID: string(rune('a' + i))})
+	}
+	service := orderservice.NewOrderService(repo, &syncOrderCache{cache: newMemoryOrderCache()}, utils.Backoff{})
+
+	for _, limit := range []int{0, -1} {
+		page, err := service.ListOrders(context.Background(), models.OrderFilter{Limit: limit})
+		require.NoError(t, err)
+		assert.Len(t, page.Orders, 20)
+		assert.NotEmpty(t, page.NextCursor)
+	}
+}
+
+func TestListOrdersHandler(t *testing.T) {
+	service := &stubOrderService{orders: map[string]*models.Order{}}
+	router := mux.NewRouter()
+	router.HandleFunc("/orders", order.NewHandler(service).ListOrders).Methods(http.MethodGet)
+
+	cursor := models.OrderCursor{DateCreated: time.Date(2021, 11, 26, 6, 22, 19, 0, time.UTC), OrderUID: "b563feb7b2b84b6test"}
+
+	tests := []struct {
+		name       string
+		query      string
+		wantStatus int
+		wantFilter models.OrderFilter
+	}{
+		{
+			name:       "defaults",
+			query:      "",
+			wantStatus: http.StatusOK,
+			// The service picks the page size when none is given
+			wantFilter: models.OrderFilter{},
+		},
+		{
+			name:       "filters",
+			query:      "?customer_id=test&payment_currency=USD&brand=Vivienne%20Sabo&nm_id=2389212&created_from=2021-11-01T00:00:00Z&limit=5&cursor=" + cursor.Encode(),
+			wantStatus: http.StatusOK,
+			wantFilter: models.OrderFilter{
+				CustomerID:      "test",
+				PaymentCurrency: "USD",
+				Brand:           "Vivienne Sabo",
+				NmID:            2389212,
+				CreatedFrom:     time.Date(2021, 11, 1, 0, 0, 0, 0, time.UTC),
+				After:           &cursor,
+				Limit:           5,
+			},
+		},
+		{
+			name:       "limit too large",
+			query:      "?limit=1000",
+			wantStatus: http.StatusBadRequest,
+		},
+		{
+			name:       "invalid cursor",
+			query:      "?cursor=%25%25",
+			wantStatus: http.StatusBadRequest,
+		},
+		{
+			name:       "invalid date",
+			query:      "?created_to=yesterday",
+			wantStatus: http.StatusBadRequest,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			service.lastFilter = models.OrderFilter{}
+			rec := httptest.NewRecorder()
+			router.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/orders"+tt.query, nil))
+			require.Equal(t, tt.wantStatus, rec.Code, rec.Body.String())
+			if tt.wantStatus == http.StatusOK {
+				assert.Equal(t, tt.wantFilter, service.lastFilter)
+			}
+		})
+	}
+}
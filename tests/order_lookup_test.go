@@ -0,0 +1,108 @@
+package tests
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gorilla/mux"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"wb-test/internal/handlers/order"
+	"wb-test/internal/models"
+	orderservice "wb-test/internal/service/order"
+	"wb-test/pkg/utils"
+)
+
+func TestLookupOrdersUsesCacheIndex(t *testing.T) {
+	sample := loadSampleOrder(t)
+	repo := &listOrderRepo{orders: []*models.Order{sample}}
+	service := orderservice.NewOrderService(repo, &syncOrderCache{cache: newMemoryOrderCache()}, utils.Backoff{})
+
+	orders, err := service.LookupOrders(context.Background(), models.LookupCustomer, sample.CustomerID)
+	require.NoError(t, err)
+	require.Len(t, orders, 1)
+	require.Len(t, repo.filters, 1)
+	assert.Equal(t, sample.CustomerID, repo.filters[0].CustomerID)
+
+	// Second lookup is served by the cache index
+	orders, err = service.LookupOrders(context.Background(), models.LookupCustomer, sample.CustomerID)
+	require.NoError(t, err)
+	require.Len(t, orders, 1)
+	assert.Len(t, repo.filters, 1)
+
+	// A new order of the same customer invalidates the index
+	other := *sample
+	other.OrderUID = "other"
+	require.NoError(t, service.ProcessOrder(context.Background(), &other))
+	repo.orders = append(repo.orders, &other)
+
+	orders, err = service.LookupOrders(context.Background(), models.LookupCustomer, sample.CustomerID)
+	require.NoError(t, err)
+	assert.Len(t, orders, 2)
+	assert.Len(t, repo.filters, 2)
+}
+
+func TestLookupOrdersHandlers(t *testing.T) {
+	sample := loadSampleOrder(t)
+	service := &stubOrderService{orders: map[string]*models.Order{sample.OrderUID: sample}}
+	h := order.NewHandler(service)
+	router := mux.NewRouter()
+	router.HandleFunc("/orders/by-track/{track_number}", h.GetOrdersByTrack).Methods(http.MethodGet)
+	router.HandleFunc("/orders/by-transaction/{transaction}", h.GetOrdersByTransaction).Methods(http.MethodGet)
+	router.HandleFunc("/customers/{customer_id}/orders", h.GetCustomerOrders).Methods(http.MethodGet)
+
+	tests := []struct {
+		name       string
+		target     string
+		wantStatus int
+		wantCount  int
+	}{
+		{
+			name:       "by track",
+			target:     "/orders/by-track/" + sample.TrackNumber,
+			wantStatus: http.StatusOK,
+			wantCount:  1,
+		},
+		{
+			name:       "by transaction",
+			target:     "/orders/by-transaction/" + sample.Payment.Transaction,
+			wantStatus: http.StatusOK,
+			wantCount:  1,
+		},
+		{
+			name:       "unknown track",
+			target:     "/orders/by-track/missing",
+			wantStatus: http.StatusNotFound,
+		},
+		{
+			name:       "customer orders",
+			target:     "/customers/" + sample.CustomerID + "/orders",
+			wantStatus: http.StatusOK,
+			wantCount:  1,
+		},
+		{
+			name:       "customer without orders",
+			target:     "/customers/nobody/orders",
+			wantStatus: http.StatusOK,
+			wantCount:  0,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rec := httptest.NewRecorder()
+			router.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, tt.target, nil))
+			require.Equal(t, tt.wantStatus, rec.Code, rec.Body.String())
+
+			if tt.wantStatus == http.StatusOK {
+				var got []models.Order
+				require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &got))
+				assert.Len(t, got, tt.wantCount)
+			}
+		})
+	}
+}
@@ -0,0 +1,174 @@
+package tests
+
+import (
+	"context"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"wb-test/internal/models"
+	orderservice "wb-test/internal/service/order"
+	"wb-test/pkg/utils"
+)
+
+type slowOrderRepo struct {
+	loads   atomic.Int32
+	release chan struct{}
+}
+
+func (r *slowOrderRepo) CreateOrder(_ context.Context, order *models.Order) error {
+	return nil
+}
+
+func (r *slowOrderRepo) CreateOrders(_ context.Context, orders []*models.Order) error {
+	return nil
+}
+
+func (r *slowOrderRepo) UpdateOrder(_ context.Context, order *models.Order) error {
+	return nil
+}
+
+func (r *slowOrderRepo) GetOrder(_ context.Context, orderUID string) (*models.Order, error) {
+	r.loads.Add(1)
+	<-r.release
+	return &models.Order{OrderUID: orderUID}, nil
+}
+
+func (r *slowOrderRepo) GetOrderHistory(_ context.Context, orderUID string) ([]models.OrderVersion, error) {
+	return nil, nil
+}
+
+func (r *slowOrderRepo) ListOrders(_ context.Context, filter models.OrderFilter) ([]*models.Order, error) {
+	return nil, nil
+}
+
+func (r *slowOrderRepo) StreamRecentOrders(_ context.Context, limit int, since time.Time, batchSize int, fn func([]*models.Order) error) error {
+	return nil
+}
+
+type syncOrderCache struct {
+	mu    sync.Mutex
+	cache *memoryOrderCache
+}
+
+func (c *syncOrderCache) GetOrder(ctx context.Context, orderUID string) (*models.Order, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.cache.GetOrder(ctx, orderUID)
+}
+
+func (c *syncOrderCache) SetOrder(ctx context.Context, orderUID string, order *models.Order) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.cache.SetOrder(ctx, orderUID, order)
+}
+
+func (c *syncOrderCache) SetOrders(ctx context.Context, orders []*models.Order) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.cache.SetOrders(ctx, orders)
+}
+
+func (c *syncOrderCache) DeleteOrder(ctx context.Context, orderUID string) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.cache.DeleteOrder(ctx, orderUID)
+}
+
+func (c *syncOrderCache) GetIndexedOrders(ctx context.Context, lookup models.OrderLookup, value string) ([]*models.Order, bool, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.cache.GetIndexedOrders(ctx, lookup, value)
+}
+
+func (c *syncOrderCache) SetIndexedOrders(ctx context.Context, lookup models.OrderLookup, value string, orders []*models.Order) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.cache.SetIndexedOrders(ctx, lookup, value, orders)
+}
+
+func (c *syncOrderCache) DeleteOrderIndexes(ctx context.Context, orders []*models.Order) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.cache.DeleteOrderIndexes(ctx, orders)
+}
+
+func TestGetOrderCoalescesConcurrentMisses(t *testing.T) {
+	repo := &slowOrderRepo{release: make(chan struct{})}
+	service := orderservice.NewOrderService(repo, &syncOrderCache{cache: newMemoryOrderCache()}, utils.Backoff{})
+
+	const callers = 20
+	var wg sync.WaitGroup
+	results := make([]*models.Order, callers)
+	errs := make([]error, callers)
+	for i := 0; i < callers; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			results[i], errs[i] = service.GetOrder(context.Background(), "hot-order")
+		}(i)
+	}
+
+	// Give the callers time to pile up behind the first load
+	time.Sleep(50 * time.Millisecond)
+	close(repo.release)
+	wg.Wait()
+
+	assert.Equal(t, int32(1), repo.loads.Load())
+	for i := 0; i < callers; i++ {
+		require.NoError(t, errs[i])
+		assert.Equal(t, "hot-order", results[i].OrderUID)
+	}
+}
+
+func TestGetOrderReportsSource(t *testing.T) {
+	repo := &slowOrderRepo{release: make(chan struct{})}
+	close(repo.release)
+	service := orderservice.NewOrderService(repo, &syncOrderCache{cache: newMemoryOrderCache()}, utils.Backoff{})
+
+	_, source, err := service.GetOrderWithSource(context.Background(), "b563feb7b2b84b6test")
+	require.NoError(t, err)
+	assert.Equal(t, models.ReadSourceDatabase, source)
+
+	_, source, err = service.GetOrderWithSource(context.Background(), "b563feb7b2b84b6test")
+	require.NoError(t, err)
+	assert.Equal(t, models.ReadSourceCache, source)
+}
+
+type requestKey struct{}
+
+// contextOrderRepo records the request value of the context each read gets
+type contextOrderRepo struct {
+	slowOrderRepo
+	seen []any
+}
+
+func (r *contextOrderRepo) GetOrder(ctx context.Context, orderUID string) (*models.Order, error) {
+	r.seen = append(r.seen, ctx.Value(requestKey{}))
+	return &models.Order{OrderUID: orderUID}, nil
+}
+
+func (r *contextOrderRepo) ListOrders(ctx context.Context, filter models.OrderFilter) ([]*models.Order, error) {
+	r.seen = append(r.seen, ctx.Value(requestKey{}))
+	return nil, nil
+}
+
+func TestReadsPassRequestContextDown(t *testing.T) {
+	repo := &contextOrderRepo{}
+	service := orderservice.NewOrderService(repo, newMemoryOrderCache(), utils.Backoff{})
+	ctx, cancel := context.WithCancel(context.WithValue(context.Background(), requestKey{}, "request"))
+	defer cancel()
+
+	_, err := service.GetOrder(ctx, "b563feb7b2b84b6test")
+	require.NoError(t, err)
+	_, err = service.ListOrders(ctx, models.OrderFilter{})
+	require.NoError(t, err)
+	_, err = service.LookupOrders(ctx, models.LookupCustomer, "test")
+	require.NoError(t, err)
+
+	assert.Equal(t, []any{"request", "request", "request"}, repo.seen)
+}
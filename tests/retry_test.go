@@ -0,0 +1,101 @@
+package tests
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"wb-test/pkg/utils"
+)
+
+func TestRetry(t *testing.T) {
+	backoff := utils.Backoff{
+		MaxAttempts:  4,
+		InitialDelay: time.Millisecond,
+		MaxDelay:     5 * time.Millisecond,
+		Multiplier:   2,
+	}
+	transient := errors.New("connection refused")
+
+	tests := []struct {
+		name      string
+		failures  int
+		err       error
+		wantCalls int
+		wantErr   bool
+	}{
+		{
+			name:      "succeeds first time",
+			failures:  0,
+			err:       transient,
+			wantCalls: 1,
+			wantErr:   false,
+		},
+		{
+			name:      "recovers from transient errors",
+			failures:  2,
+			err:       transient,
+			wantCalls: 3,
+			wantErr:   false,
+		},
+		{
+			name:      "gives up after max attempts",
+			failures:  10,
+			err:       transient,
+			wantCalls: 4,
+			wantErr:   true,
+		},
+		{
+			name:      "does not retry permanent errors",
+			failures:  10,
+			err:       fmt.Errorf("wrapped: %w", utils.Permanent(errors.New("bad payload"))),
+			wantCalls: 1,
+			wantErr:   true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			calls := 0
+			err := utils.Retry(context.Background(), backoff, func() error {
+				calls++
+				if calls <= tt.failures {
+					return tt.err
+				}
+				return nil
+			}, nil)
+
+			assert.Equal(t, tt.wantCalls, calls)
+			if tt.wantErr {
+				assert.Error(t, err)
+			} else {
+				assert.NoError(t, err)
+			}
+		})
+	}
+}
+
+func TestBackoffDelay(t *testing.T) {
+	backoff := utils.Backoff{
+		InitialDelay: 10 * time.Millisecond,
+		MaxDelay:     40 * time.Millisecond,
+		Multiplier:   2,
+	}
+
+	for retry := 1; retry <= 10; retry++ {
+		delay := backoff.Delay(retry)
+		assert.Greater(t, delay, time.Duration(0))
+		assert.LessOrEqual(t, delay, 40*time.Millisecond)
+	}
+}
+
+func TestErrorClassification(t *testing.T) {
+	assert.False(t, utils.IsTransient(nil))
+	assert.True(t, utils.IsTransient(errors.New("timeout")))
+	assert.True(t, utils.IsPermanent(fmt.Errorf("ctx: %w", utils.Permanent(errors.New("bad")))))
+	assert.Nil(t, utils.Permanent(nil))
+}
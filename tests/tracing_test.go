@@ -0,0 +1,110 @@
+package tests
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+
+	"github.com/nats-io/nats.go"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/codes"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	"go.opentelemetry.io/otel/trace"
+
+	"wb-test/internal/models"
+	orderservice "wb-test/internal/service/order"
+	"wb-test/pkg/broker"
+	"wb-test/pkg/config"
+	"wb-test/pkg/tracing"
+	"wb-test/pkg/utils"
+)
+
+var (
+	spanExporter     = tracetest.NewInMemoryExporter()
+	spanExporterOnce sync.Once
+)
+
+// recordSpans installs an in-memory tracer provider. Package tracers bind to
+// the first global provider, so it is installed once and reset per test.
+func recordSpans(t *testing.T) *tracetest.InMemoryExporter {
+	spanExporterOnce.Do(func() {
+		otel.SetTracerProvider(sdktrace.NewTracerProvider(sdktrace.WithSyncer(spanExporter)))
+	})
+	spanExporter.Reset()
+	t.Cleanup(spanExporter.Reset)
+	return spanExporter
+}
+
+func findSpan(t *testing.T, spans tracetest.SpanStubs, name string) tracetest.SpanStub {
+	for _, span := range spans {
+		if span.Name == name {
+			return span
+		}
+	}
+	require.Failf(t, "span not recorded", "no span named %q", name)
+	return tracetest.SpanStub{}
+}
+
+type failingOrderRepo struct {
+	slowOrderRepo
+}
+
+func (r *failingOrderRepo) CreateOrder(_ context.Context, order *models.Order) error {
+	return utils.Permanent(errors.New("constraint violation"))
+}
+
+func TestProcessOrderContinuesCallerTrace(t *testing.T) {
+	exporter := recordSpans(t)
+	service := orderservice.NewOrderService(&slowOrderRepo{}, &syncOrderCache{cache: newMemoryOrderCache()}, utils.Backoff{})
+
+	ctx, parent := otel.Tracer("tests").Start(context.Background(), "orders.new process")
+	require.NoError(t, service.ProcessOrder(ctx, &models.Order{OrderUID: "b563feb7b2b84b6test"}))
+	parent.End()
+
+	span := findSpan(t, exporter.GetSpans(), "OrderService.ProcessOrder")
+	assert.Equal(t, parent.SpanContext().TraceID(), span.SpanContext.TraceID())
+	assert.Equal(t, parent.SpanContext().SpanID(), span.Parent.SpanID())
+	assert.Equal(t, codes.Unset, span.Status.Code)
+}
+
+func TestProcessOrderRecordsErrorOnSpan(t *testing.T) {
+	exporter := recordSpans(t)
+	service := orderservice.NewOrderService(&failingOrderRepo{}, &syncOrderCache{cache: newMemoryOrderCache()}, utils.Backoff{})
+
+	require.Error(t, service.ProcessOrder(context.Background(), &models.Order{OrderUID: "b563feb7b2b84b6test"}))
+
+	span := findSpan(t, exporter.GetSpans(), "OrderService.ProcessOrder")
+	assert.Equal(t, codes.Error, span.Status.Code)
+	assert.NotEmpty(t, span.Events)
+}
+
+func TestTraceContextRoundTripsThroughNATSHeaders(t *testing.T) {
+	recordSpans(t)
+	shutdown, err := tracing.Init(context.Background(), config.TracingConfig{Exporter: tracing.ExporterNone})
+	require.NoError(t, err)
+	defer shutdown(context.Background())
+
+	ctx, span := otel.Tracer("tests").Start(context.Background(), "orders.new publish")
+	defer span.End()
+
+	header := nats.Header{}
+	broker.InjectTrace(ctx, header)
+	assert.NotEmpty(t, header.Get("traceparent"))
+
+	extracted := trace.SpanContextFromContext(broker.ExtractTrace(context.Background(), header))
+	assert.True(t, extracted.IsRemote())
+	assert.Equal(t, span.SpanContext().TraceID(), extracted.TraceID())
+	assert.Equal(t, span.SpanContext().SpanID(), extracted.SpanID())
+
+	// Messages without headers start a new trace
+	assert.False(t, trace.SpanContextFromContext(broker.ExtractTrace(context.Background(), nil)).IsValid())
+}
+
+func TestTracingInitRejectsUnknownExporter(t *testing.T) {
+	_, err := tracing.Init(context.Background(), config.TracingConfig{Exporter: "jaeger"})
+	assert.Error(t, err)
+}
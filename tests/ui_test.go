@@ -0,0 +1,44 @@
+package tests
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	handler "wb-test/internal/handlers"
+	"wb-test/internal/models"
+	"wb-test/pkg/utils/jwt"
+)
+
+func TestUIServed(t *testing.T) {
+	service := &stubOrderService{orders: map[string]*models.Order{}}
+	router := handler.InitRouter(handler.NewHandler(service, nil, jwt.NewDevKeySet(), nil, nil))
+
+	rec := httptest.NewRecorder()
+	router.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/", nil))
+	assert.Equal(t, http.StatusFound, rec.Code)
+	assert.Equal(t, "/ui/", rec.Header().Get("Location"))
+
+	tests := []struct {
+		path        string
+		contentType string
+		contains    string
+	}{
+		{path: "/ui/", contentType: "text/html", contains: `id="search"`},
+		{path: "/ui/app.js", contentType: "javascript", contains: "X-Order-Source"},
+		{path: "/ui/style.css", contentType: "text/css", contains: ".badge"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.path, func(t *testing.T) {
+			rec := httptest.NewRecorder()
+			router.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, tt.path, nil))
+			require.Equal(t, http.StatusOK, rec.Code)
+			assert.Contains(t, rec.Header().Get("Content-Type"), tt.contentType)
+			assert.Contains(t, rec.Body.String(), tt.contains)
+		})
+	}
+}
@@ -0,0 +1,97 @@
+package tests
+
+import (
+	"encoding/json"
+	"os"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"wb-test/internal/models"
+	"wb-test/internal/validation"
+)
+
+func loadSampleOrder(t *testing.T) *models.Order {
+	t.Helper()
+
+	data, err := os.ReadFile("../sample_payload.json")
+	require.NoError(t, err)
+
+	var order models.Order
+	require.NoError(t, json.Unmarshal(data, &order))
+	return &order
+}
+
+func TestValidateOrder(t *testing.T) {
+	tests := []struct {
+		name       string
+		mutate     func(o *models.Order)
+		wantFields []string
+	}{
+		{
+			name:       "sample payload is valid",
+			mutate:     func(o *models.Order) {},
+			wantFields: nil,
+		},
+		{
+			name:       "missing order uid",
+			mutate:     func(o *models.Order) { o.OrderUID = "" },
+			wantFields: []string{"order_uid"},
+		},
+		{
+			name:       "no items",
+			mutate:     func(o *models.Order) { o.Items = nil },
+			wantFields: []string{"items"},
+		},
+		{
+			name:       "bad email and phone",
+			mutate:     func(o *models.Order) { o.Delivery.Email = "not-an-email"; o.Delivery.Phone = "call me" },
+			wantFields: []string{"delivery.email", "delivery.phone"},
+		},
+		{
+			name:       "bad currency and locale",
+			mutate:     func(o *models.Order) { o.Payment.Currency = "usd"; o.Locale = "english" },
+			wantFields: []string{"payment.currency", "locale"},
+		},
+		{
+			name:       "amount does not add up",
+			mutate:     func(o *models.Order) { o.Payment.Amount = 1 },
+			wantFields: []string{"payment.amount"},
+		},
+		{
+			name: "goods total does not match items",
+			mutate: func(o *models.Order) {
+				o.Payment.GoodsTotal = 300
+				o.Payment.Amount = 300 + o.Payment.DeliveryCost + o.Payment.CustomFee
+			},
+			wantFields: []string{"payment.goods_total"},
+		},
+		{
+			name:       "item track number differs",
+			mutate:     func(o *models.Order) { o.Items[0].TrackNumber = "OTHER" },
+			wantFields: []string{"items[0].track_number"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			order := loadSampleOrder(t)
+			tt.mutate(order)
+
+			err := validation.ValidateOrder(order)
+			if tt.wantFields == nil {
+				assert.NoError(t, err)
+				return
+			}
+
+			var verrs validation.Errors
+			require.ErrorAs(t, err, &verrs)
+			var fields []string
+			for _, d := range verrs.Details() {
+				fields = append(fields, d.Field)
+			}
+			assert.ElementsMatch(t, tt.wantFields, fields)
+		})
+	}
+}
@@ -0,0 +1,100 @@
+package tests
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"wb-test/internal/models"
+	orderservice "wb-test/internal/service/order"
+	"wb-test/pkg/config"
+	"wb-test/pkg/utils"
+)
+
+// warmupOrderRepo streams its orders in batches and records the arguments
+type warmupOrderRepo struct {
+	slowOrderRepo
+	orders []*models.Order
+
+	limit     int
+	since     time.Time
+	batchSize int
+}
+
+func (r *warmupOrderRepo) StreamRecentOrders(_ context.Context, limit int, since time.Time, batchSize int, fn func([]*models.Order) error) error {
+	r.limit, r.since, r.batchSize = limit, since, batchSize
+
+	orders := r.orders
+	if limit > 0 && len(orders) > limit {
+		orders = orders[:limit]
+	}
+	for start := 0; start < len(orders); start += batchSize {
+		if err := fn(orders[start:min(start+batchSize, len(orders))]); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+type failingSetOrdersCache struct {
+	memoryOrderCache
+}
+
+func (c *failingSetOrdersCache) SetOrders(context.Context, []*models.Order) error {
+	return errors.New("redis is down")
+}
+
+func warmupOrders(n int) []*models.Order {
+	orders := make([]*models.Order, n)
+	for i := range orders {
+		orders[i] = &models.Order{OrderUID: string(rune('a' + i))}
+	}
+	return orders
+}
+
+func TestWarmupCacheRestoresAllStreamedOrders(t *testing.T) {
+	repo := &warmupOrderRepo{orders: warmupOrders(5)}
+	cache := newMemoryOrderCache()
+	service := orderservice.NewOrderService(repo, cache, utils.Backoff{})
+
+	restored, err := service.WarmupCache(context.Background(), 0, time.Hour, 2)
+	require.NoError(t, err)
+	assert.Equal(t, 5, restored)
+	assert.Len(t, cache.orders, 5)
+
+	// Age only: no count cap, a lower bound about an hour back
+	assert.Equal(t, 0, repo.limit)
+	assert.Equal(t, 2, repo.batchSize)
+	assert.WithinDuration(t, time.Now().Add(-time.Hour), repo.since, time.Minute)
+}
+
+func TestWarmupCacheByCountHasNoAgeBound(t *testing.T) {
+	repo := &warmupOrderRepo{orders: warmupOrders(5)}
+	service := orderservice.NewOrderService(repo, newMemoryOrderCache(), utils.Backoff{})
+
+	restored, err := service.WarmupCache(context.Background(), 3, 0, 2)
+	require.NoError(t, err)
+	assert.Equal(t, 3, restored)
+	assert.True(t, repo.since.IsZero())
+}
+
+func TestWarmupCacheStopsOnCacheError(t *testing.T) {
+	repo := &warmupOrderRepo{orders: warmupOrders(5)}
+	service := orderservice.NewOrderService(repo, &failingSetOrdersCache{*newMemoryOrderCache()}, utils.Backoff{})
+
+	restored, err := service.WarmupCache(context.Background(), 0, 0, 2)
+	assert.Error(t, err)
+	assert.Equal(t, 0, restored)
+}
+
+func TestConfigRejectsNegativeWarmupLimit(t *testing.T) {
+	t.Setenv("DB_DSN", "postgres://localhost/wb_orders")
+	t.Setenv("CACHE_WARMUP_LIMIT", "-1")
+
+	_, err := config.Load()
+	assert.ErrorContains(t, err, "CACHE_WARMUP_LIMIT")
+}